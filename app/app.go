@@ -1,12 +1,12 @@
 package app
 
 import (
-	"bytes"
 	"crypto/rand"
 	"embed"
 	"encoding/hex"
 	"fmt"
 	"html/template"
+	"io"
 	"io/fs"
 	"log"
 	"net/http"
@@ -27,12 +27,21 @@ func NewHTTPHandler(devMode bool) http.Handler {
 
 	// Init emailer
 	var emailer Emailer
+	var err error
+	var smtpSink *smtpSink
 	switch {
 	default:
-		emailer = newSMTPEmailer(config) // using sendinblue for example
+		emailer, err = newEmailTransport(config) // using sendinblue for example
+	case devMode && os.Getenv("DEV_SMTP_SINK") != "":
+		// Send emails with the real SMTP client to an in-process server (ex: DEV_SMTP_SINK=localhost:2525)
+		smtpSink = mustStartSMTPSink(os.Getenv("DEV_SMTP_SINK"))
+		emailer, err = newEmailTransport(smtpSink.config(config))
 	case devMode:
 		emailer = newMockEmailer(os.Stdout, nil)
 	}
+	if err != nil {
+		panic(err)
+	}
 
 	// Init logger
 	log.SetFlags(log.LUTC | log.Llongfile)
@@ -40,6 +49,11 @@ func NewHTTPHandler(devMode bool) http.Handler {
 	// Init DB
 	db := newBoltDB()
 
+	// Queue emails in the DB and deliver them in the background (with retries)
+	outbox := newEmailOutbox(db.f, emailer)
+	go outbox.doDeliver()
+	emailer = outbox.enqueue
+
 	// Start analytics reporting background job
 	go doPeriodicHealthReport(config, emailer, db)
 
@@ -49,24 +63,69 @@ func NewHTTPHandler(devMode bool) http.Handler {
 	// Init HTTP router
 	router := pat.New()
 
-	// Register routes
-	router.Add(http.MethodGet, "/", prerenderAndServePage("home.gohtml", english, nil))
-	router.Add(http.MethodGet, "/info", prerenderAndServePage("info.gohtml", english, nil))
-	router.Add(http.MethodGet, "/contact", prerenderAndServePage("contact.gohtml", english, nil))
-	router.Add(http.MethodGet, "/resume", prerenderAndServePage("resume.gohtml", english, resumeData))
-	router.Add(http.MethodGet, "/resume/fr", prerenderAndServePage("resume.gohtml", french, resumeData))
-	router.Add(http.MethodGet, "/resume.pdf", generateAndServeResumeFile(resumeData, english))
-	router.Add(http.MethodGet, "/resume_fr.pdf", generateAndServeResumeFile(resumeData, french))
+	// Render website and keep it up to date with the resume data
+	site, err := newSite(config, emailer, db)
+	if err != nil {
+		panic(err)
+	}
+	go site.doReloadOnChange()
+
+	// Register routes (files first since "/resume/:variant" would also match "/resume/devops.pdf")
+	servePrerendered := http.HandlerFunc(site.servePrerendered)
+	serveResumePDF := http.HandlerFunc(site.serveResumePDF)
+	for _, l := range supportedLangs {
+		router.Add(http.MethodGet, resumeFilePath("", l, ".pdf"), serveResumePDF)
+		for _, format := range resumeFileFormats {
+			router.Add(http.MethodGet, resumeFilePath("", l, format.ext), servePrerendered)
+		}
+	}
+	router.Add(http.MethodGet, "/resume/:variant.pdf", serveResumePDF)
+
+	// Redirect former URLs of translated résumé pages (ex: "/resume/devops/fr" to "/fr/resume/devops"),
+	// before pages since "/resume/:variant" would also match "/resume/fr"
+	for _, l := range supportedLangs {
+		if l == english {
+			continue
+		}
+		router.Add(http.MethodGet, "/resume/"+string(l), http.RedirectHandler(resumePagePath("", l), http.StatusMovedPermanently))
+		router.Add(http.MethodGet, "/resume/:variant/"+string(l), redirectToResumePage(l))
+	}
+
+	// Pages are available in every language (ex: "/contact" and "/fr/contact"),
+	// the contact page is rendered on request (before other pages) since its form holds a new token every time
+	servePage := newLangNegotiationMiddleware()(servePrerendered)
+	serveContactPage := newLangNegotiationMiddleware()(http.HandlerFunc(site.serveContactPage))
+	for _, l := range supportedLangs {
+		router.Add(http.MethodGet, localizedPath(l, "/contact"), serveContactPage)
+	}
+	for _, l := range supportedLangs {
+		for _, route := range pageRoutes {
+			router.Add(http.MethodGet, localizedPath(l, route.Pattern), servePage)
+		}
+	}
+	for _, l := range supportedLangs {
+		router.Add(http.MethodPost, localizedPath(l, "/contact"), http.HandlerFunc(site.handleContactForm))
+	}
+	router.Add(http.MethodGet, "/sitemap.xml", servePrerendered)
+	router.Add(http.MethodGet, "/robots.txt", servePrerendered)
+	router.Add(http.MethodGet, "/og/", servePrerendered) // social preview images of pages
+	if smtpSink != nil {
+		router.Add(http.MethodGet, "/_dev/mail", smtpSink)
+	}
 
 	// Serve static files
 	fsys, err := fs.Sub(staticFilesFS, "static")
 	if err != nil {
 		log.Fatal(err)
 	}
-	router.NotFound = http.FileServer(http.FS(fsys))
+	staticFiles, err := loadStaticFiles(fsys)
+	if err != nil {
+		panic(err)
+	}
+	router.NotFound = staticFiles
 
 	// Wrap middleware
-	out := newRequestTrackingMiddleware(db)(router)
+	out := newRequestTrackingMiddleware(config, db)(router)
 	out = newRecoveryMiddleware(config, emailer)(out)
 
 	// Return HTTP handler
@@ -87,33 +146,77 @@ var layoutTmpls = []string{
 	"ui/_footer.gohtml",
 }
 
-func prerenderAndServePage(pageName string, l lang, data any) http.HandlerFunc {
-	tmpl := template.Must(template.ParseFS(uiFS, append(layoutTmpls, "ui/"+pageName)...))
-	buf := &bytes.Buffer{}
-	err := tmpl.ExecuteTemplate(buf, "page_layout", map[string]any{
+// Functions available in all templates.
+var templateFuncs = template.FuncMap{
+	"t":         translate,
+	"path":      localizedPath,
+	"langs":     func() []lang { return supportedLangs },
+	"ogLocale":  func(l lang) string { return ogLocales[l] },
+	"number":    formatInt,
+	"decimal":   formatDecimal,
+	"date":      formatDate,
+	"monthYear": formatMonthYear,
+}
+
+func parseTemplates(pageName string) (*template.Template, error) {
+	return template.New(pageName).Funcs(templateFuncs).ParseFS(uiFS, append(layoutTmpls, "ui/"+pageName)...)
+}
+
+// The résumé is used for the structured data describing the website owner.
+func prerenderPage(w io.Writer, p page, l lang, content resume) error {
+	tmpl, err := parseTemplates(p.Template)
+	if err != nil {
+		return err
+	}
+	return executePage(w, tmpl, p, l, content)
+}
+
+// Renders a page with its parsed templates (see parseTemplates).
+func executePage(w io.Writer, tmpl *template.Template, p page, l lang, content resume) error {
+	return tmpl.ExecuteTemplate(w, "page_layout", map[string]any{
 		"Lang":     l,
+		"Path":     p.Path, // used to link to the same page in other languages
+		"TitleKey": p.TitleKey,
+		"Robots":   p.Robots,
 		"Branding": defaultBranding,
-		"Data":     data,
+		"Data":     p.Data,
+		"SEO": map[string]any{
+			"URL":         defaultBranding.URL + localizedPath(l, p.Path),
+			"Locale":      ogLocales[l],
+			"Image":       defaultBranding.URL + ogImagePath(l, p.Path),
+			"ImageWidth":  ogImageWidth,
+			"ImageHeight": ogImageHeight,
+			"Person":      personJSONLD(content, l),
+		},
 	})
-	if err != nil {
-		panic(err)
-	}
-	return func(w http.ResponseWriter, r *http.Request) {
-		w.Write(buf.Bytes())
-	}
 }
 
-var errPageTmpl = template.Must(template.ParseFS(uiFS, append(layoutTmpls, "ui/_error.gohtml")...))
+var errPageTmpl = template.Must(parseTemplates("_error.gohtml"))
 
-func respondErrorPage(w http.ResponseWriter, status int, message string) {
+// Responds with an error page in the language of the requested page,
+// the message is the key of a translated message (ex: "error.page_not_found").
+func respondErrorPage(w http.ResponseWriter, r *http.Request, status int, messageKey string) {
+	l, path := splitLangPath(r.URL.Path)
+	message, err := translate(l, messageKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", htmlContentType)
 	w.WriteHeader(status)
-	err := errPageTmpl.ExecuteTemplate(w, "page_layout", map[string]any{
-		"Status":       strconv.Itoa(status),
-		"StatusText":   http.StatusText(status),
-		"ErrorMessage": message,
+	err = errPageTmpl.ExecuteTemplate(w, "page_layout", map[string]any{
+		"Lang":     l,
+		"Path":     path,
+		"Robots":   robotsNoIndex,
+		"Branding": defaultBranding,
+		"Data": map[string]any{
+			"Status":       strconv.Itoa(status),
+			"StatusText":   http.StatusText(status),
+			"ErrorMessage": message,
+		},
 	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		log.Println(err)
 		return
 	}
 }
@@ -134,7 +237,7 @@ func newRecoveryMiddleware(config *Config, emailer Emailer) func(http.Handler) h
 				if err := recover(); err != nil {
 					err := fmt.Errorf("panic: %s\n%s", err, debug.Stack())
 					log.Println(err.Error())
-					respondErrorPage(w, http.StatusInternalServerError, "fatal error")
+					respondErrorPage(w, r, http.StatusInternalServerError, "error.fatal")
 					err = sendEmailToAdmin(config, emailer, "Panic from juliensellier.com", err.Error())
 					if err != nil {
 						log.Println(err)
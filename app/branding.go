@@ -2,12 +2,40 @@ package app
 
 type branding struct {
 	Name             string
+	URL              string // website URL, without trailing slash
 	ContactEmailAddr string
 	Font             string
+	// Pixel-art logo, drawn in the header and in social preview images
+	LogoWidth  int
+	LogoHeight int
+	Logo       []logoRect
 }
 
+// Filled rectangle of the logo (in logo pixels).
+type logoRect struct{ X, Y, W, H int }
+
 var defaultBranding = branding{
 	Name:             "Julien Sellier",
+	URL:              "https://www.juliensellier.com",
 	ContactEmailAddr: "admin@juliensellier.com",
 	Font:             "JetBrainsMono",
+	LogoWidth:        13,
+	LogoHeight:       11,
+	Logo: []logoRect{
+		{X: 0, Y: 0, W: 1, H: 11},
+		{X: 12, Y: 0, W: 1, H: 11},
+		{X: 1, Y: 0, W: 3, H: 1},
+		{X: 9, Y: 0, W: 3, H: 1},
+		{X: 9, Y: 10, W: 3, H: 1},
+		{X: 1, Y: 10, W: 3, H: 1},
+		{X: 3, Y: 2, W: 2, H: 1},
+		{X: 8, Y: 2, W: 2, H: 1},
+		{X: 2, Y: 3, W: 4, H: 1},
+		{X: 7, Y: 3, W: 4, H: 1},
+		{X: 2, Y: 4, W: 9, H: 1},
+		{X: 3, Y: 5, W: 7, H: 1},
+		{X: 4, Y: 6, W: 5, H: 1},
+		{X: 5, Y: 7, W: 3, H: 1},
+		{X: 6, Y: 8, W: 1, H: 1},
+	},
 }
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Responses are compressed once when they are rendered (or loaded for static files),
+// the encoding is then chosen per request from the Accept-Encoding header.
+
+// Compressed variants are only kept if they are at least 10% smaller than the original content
+// (fonts and PDFs are often already compressed).
+func worthCompressing(original, compressed []byte) bool {
+	return len(compressed) < len(original)*9/10
+}
+
+func compressGzip(content []byte) []byte {
+	buf := &bytes.Buffer{}
+	zw, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
+	if err != nil {
+		panic(err)
+	}
+	_, err = zw.Write(content)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil || !worthCompressing(content, buf.Bytes()) {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+func compressBrotli(content []byte) []byte {
+	buf := &bytes.Buffer{}
+	bw := brotli.NewWriterLevel(buf, 9) // the best compression level is several times slower for a few percents
+	_, err := bw.Write(content)
+	if err == nil {
+		err = bw.Close()
+	}
+	if err != nil || !worthCompressing(content, buf.Bytes()) {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// Returns the preferred encoding ("br", "gzip" or "" for identity) among the available ones,
+// according to the quality values of the Accept-Encoding header (Brotli wins ties).
+func negotiateEncoding(acceptEncoding string, hasBrotli, hasGzip bool) string {
+	qualities := map[string]float64{}
+	for _, v := range parseQualityValues(acceptEncoding) {
+		qualities[v.value] = v.quality
+	}
+	qualityOf := func(encoding string) float64 {
+		if q, ok := qualities[encoding]; ok {
+			return q
+		}
+		return qualities["*"]
+	}
+
+	best, bestQuality := "", 0.0
+	if hasBrotli && qualityOf("br") > bestQuality {
+		best, bestQuality = "br", qualityOf("br")
+	}
+	if hasGzip && qualityOf("gzip") > bestQuality {
+		best = "gzip"
+	}
+	return best
+}
+
+type qualityValue struct {
+	value   string // lowercased
+	quality float64
+}
+
+// Parses a header listing values with optional weights (ex: "fr-CH, fr;q=0.9, *;q=0.5"),
+// values are returned in the header order and malformed weights are skipped.
+func parseQualityValues(header string) []qualityValue {
+	out := []qualityValue{}
+	for _, part := range strings.Split(header, ",") {
+		value, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			q, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64)
+			if err != nil {
+				continue
+			}
+			quality = q
+		}
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			out = append(out, qualityValue{value: value, quality: quality})
+		}
+	}
+	return out
+}
+
+// Returns a snapshot of the embedded static files, keyed by URL path (ex: "/favicon.ico").
+// Files are dated with the modification time of the executable, so that their Last-Modified date only changes on deployment.
+func loadStaticFiles(fsys fs.FS) (snapshot, error) {
+	snap := snapshot{}
+	modTime := executableModTime()
+	err := fs.WalkDir(fsys, ".", func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, fpath)
+		if err != nil {
+			return err
+		}
+		contentType := mime.TypeByExtension(path.Ext(fpath))
+		if contentType == "" {
+			contentType = http.DetectContentType(content)
+		}
+		snap.add("/"+fpath, &prerenderedFile{Content: content, ContentType: contentType, ModTime: modTime})
+		return nil
+	})
+	return snap, err
+}
+
+// Returns the current time if the executable can't be found.
+func executableModTime() time.Time {
+	modTime := time.Time{}
+	if executable, err := os.Executable(); err == nil {
+		modTime = fileModTime(executable)
+	}
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+	return modTime.UTC().Truncate(time.Second)
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// Restarting the server must not change the modification time of static files.
+func TestLoadStaticFilesModTime(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fileModTime(executable).UTC().Truncate(time.Second)
+
+	fsys := fstest.MapFS{"favicon.ico": &fstest.MapFile{Data: []byte("icon")}}
+	for i := 0; i < 2; i++ { // simulates a restart
+		snap, err := loadStaticFiles(fsys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := snap["/favicon.ico"].ModTime; !got.Equal(want) {
+			t.Fatalf("got modification time %s, want the modification time of the executable (%s)", got, want)
+		}
+	}
+}
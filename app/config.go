@@ -11,7 +11,22 @@ type Config struct {
 	SMTPUsername   string `json:"smtp_username"`
 	SMTPSender     string `json:"smtp_sender"`
 	SMTPPassword   string `json:"smtp_password"`
+	SMTPTLSMode    string `json:"smtp_tls_mode"`   // "starttls" (default), "implicit" or "none"
+	EmailTransport string `json:"email_transport"` // "smtp" (default), "sendmail" or "file"
+	SendmailPath   string `json:"sendmail_path"`   // optional, "/usr/sbin/sendmail" by default
+	EmailDropDir   string `json:"email_drop_dir"`  // maildir where emails are written with the "file" transport
 	AdminEmailAddr string `json:"admin_email_addr"`
+	ResumeDataPath string `json:"resume_data_path"` // optional, the embedded résumé data is used by default
+	RenderDate     string `json:"render_date"`      // optional, pins the render date (YYYY-MM-DD) to get reproducible files
+	FormSecret     string `json:"form_secret"`      // key used to sign the tokens of forms
+
+	// Optional IP addresses or CIDR ranges (ex: "127.0.0.1") of the reverse proxies allowed to set the X-Forwarded-For header
+	TrustedProxies []string `json:"trusted_proxies"`
+
+	// Optional DKIM signing of emails (the key is a PEM encoded RSA or Ed25519 private key)
+	DKIMDomain         string `json:"dkim_domain"`
+	DKIMSelector       string `json:"dkim_selector"`
+	DKIMPrivateKeyPath string `json:"dkim_private_key_path"`
 }
 
 func mustLoadConfig(fpath string) *Config {
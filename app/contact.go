@@ -0,0 +1,276 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Messages sent with the contact form are stored in the DB and forwarded to the admin by email.
+
+type contactMessage struct {
+	ID          string
+	CreatedAt   time.Time
+	Lang        lang
+	Name        string
+	Email       string
+	Message     string
+	VisitorHash string
+}
+
+// contactForm holds the values submitted with the contact form
+// and the validation errors (as message keys, by field name) when it is displayed again.
+type contactForm struct {
+	Name    string
+	Email   string
+	Message string
+	Token   string // signed time at which the form was displayed
+	Errors  map[string]string
+}
+
+// Name of the contact form in form tokens and rejected submissions.
+const contactFormName = "contact"
+
+const (
+	maxContactNameLength    = 100
+	maxContactEmailLength   = 254
+	maxContactMessageLength = 5000
+	maxContactFormSize      = 64 << 10 // in bytes
+)
+
+// Returns false if any field is invalid, the errors are then set on the form.
+func (form *contactForm) validate() bool {
+	form.Errors = map[string]string{}
+	switch {
+	case form.Name == "":
+		form.Errors["name"] = "contact.error.name_required"
+	case utf8.RuneCountInString(form.Name) > maxContactNameLength:
+		form.Errors["name"] = "contact.error.name_too_long"
+	case strings.IndexFunc(form.Name, unicode.IsControl) >= 0: // the name is used in the email subject
+		form.Errors["name"] = "contact.error.name_invalid"
+	}
+	if addr, err := mail.ParseAddress(form.Email); err != nil || addr.Address != form.Email || len(form.Email) > maxContactEmailLength {
+		form.Errors["email"] = "contact.error.email_invalid"
+	}
+	switch {
+	case form.Message == "":
+		form.Errors["message"] = "contact.error.message_required"
+	case utf8.RuneCountInString(form.Message) > maxContactMessageLength:
+		form.Errors["message"] = "contact.error.message_too_long"
+	}
+	return len(form.Errors) == 0
+}
+
+// Handles contact form submissions: invalid forms are displayed again with the errors,
+// valid messages are stored and forwarded to the admin, then the visitor is redirected to a confirmation page.
+func (s *site) handleContactForm(w http.ResponseWriter, r *http.Request) {
+	l, _ := splitLangPath(r.URL.Path)
+	r.Body = http.MaxBytesReader(w, r.Body, maxContactFormSize)
+	err := r.ParseForm()
+	if err != nil {
+		respondErrorPage(w, r, http.StatusBadRequest, "error.invalid_form")
+		return
+	}
+	form := &contactForm{
+		Name:    strings.TrimSpace(r.PostForm.Get("name")),
+		Email:   strings.TrimSpace(r.PostForm.Get("email")),
+		Message: strings.TrimSpace(r.PostForm.Get("message")),
+	}
+	visitorHash := newVisitorHash(r, s.config.TrustedProxies)
+	now := time.Now()
+
+	// Reject bots, they are redirected to the confirmation page as if the message was sent
+	if r.PostForm.Get(honeypotFieldName) != "" {
+		s.rejectContactForm(w, r, visitorHash, "honeypot")
+		return
+	}
+	switch err := checkFormToken([]byte(s.config.FormSecret), contactFormName, r.PostForm.Get("token"), now); err {
+	case errFormTooFast:
+		s.rejectContactForm(w, r, visitorHash, "too_fast")
+		return
+	case errFormTokenInvalid:
+		s.rejectContactForm(w, r, visitorHash, "invalid_token")
+		return
+	case errFormTokenExpired:
+		form.validate()
+		form.Errors["form"] = "contact.error.expired"
+		s.respondContactForm(w, r, form, http.StatusUnprocessableEntity)
+		return
+	}
+	if !form.validate() {
+		s.respondContactForm(w, r, form, http.StatusUnprocessableEntity)
+		return
+	}
+	if spamScore(form.Name, form.Message) > maxSpamScore {
+		s.rejectContactForm(w, r, visitorHash, "spam_content")
+		return
+	}
+
+	// Limit the number of messages per visitor (DB keys are precise to the second, hence the upper bound)
+	count, err := s.db.CountContactMessagesFromVisitor(visitorHash, now.Add(-submissionRateLimitPeriod), now.Add(time.Second))
+	if err != nil {
+		log.Println(err)
+		respondErrorPage(w, r, http.StatusInternalServerError, "error.contact_failed")
+		return
+	}
+	if count >= maxSubmissionsPerVisitor {
+		s.storeRejectedSubmission(visitorHash, "rate_limited")
+		respondErrorPage(w, r, http.StatusTooManyRequests, "error.too_many_messages")
+		return
+	}
+
+	// Store message
+	msg := &contactMessage{
+		ID:          newID(16),
+		CreatedAt:   now,
+		Lang:        l,
+		Name:        form.Name,
+		Email:       form.Email,
+		Message:     form.Message,
+		VisitorHash: visitorHash,
+	}
+	err = s.db.StoreContactMessage(msg)
+	if err != nil {
+		log.Println(err)
+		respondErrorPage(w, r, http.StatusInternalServerError, "error.contact_failed")
+		return
+	}
+
+	// Forward message to admin (the message is stored anyway if this fails),
+	// emails are keyed by the form token so that a form submitted twice (ex: double click) only sends them once
+	token := r.PostForm.Get("token")
+	err = s.emailer(&Email{
+		From:           s.config.SMTPSender,
+		To:             []string{s.config.AdminEmailAddr},
+		Subject:        "New contact message from " + msg.Name,
+		PlainTextBody:  fmt.Sprintf("From: %s <%s>\nLanguage: %s\nMessage ID: %s\n\n%s", msg.Name, msg.Email, msg.Lang, msg.ID, msg.Message),
+		IdempotencyKey: "contact_forward:" + token,
+	})
+	if err != nil {
+		log.Println(err)
+	}
+
+	// Confirm to the visitor that the message was received
+	err = s.sendContactReply(msg, "contact_reply:"+token)
+	if err != nil {
+		log.Println(err)
+	}
+
+	http.Redirect(w, r, localizedPath(l, "/contact/sent"), http.StatusSeeOther)
+}
+
+var contactReplyEmailTmpl = template.Must(template.New("contact_reply_email.gohtml").Funcs(templateFuncs).ParseFS(uiFS, "ui/contact_reply_email.gohtml"))
+
+// Sends an automatic reply to the sender of the message, with the résumé PDF attached (in the language of the message).
+// Nothing typed by the sender (message or name) is included so that the form can't be used to send arbitrary content to anyone,
+// and replies to the same address are rate limited (the message must already be stored, since it is counted).
+func (s *site) sendContactReply(msg *contactMessage, idempotencyKey string) error {
+	count, err := s.db.CountContactMessagesFromEmail(msg.Email, msg.CreatedAt.Add(-replyRateLimitPeriod), msg.CreatedAt.Add(time.Second))
+	if err != nil {
+		return err
+	}
+	if count > maxRepliesPerRecipient {
+		return nil
+	}
+
+	email := &Email{From: s.config.SMTPSender, To: []string{msg.Email}, IdempotencyKey: idempotencyKey}
+	if pdf, ok := s.currentState().files[resumePDFKey(resumeFilePath("", msg.Lang, ".pdf"), defaultPDFTheme)]; ok {
+		email.Attachments = append(email.Attachments, emailAttachment{Filename: pdf.Filename, ContentType: pdf.ContentType, Content: pdf.Content})
+	}
+
+	// Subject and plain text body
+	texts := []string{}
+	for _, message := range []struct {
+		key  string
+		args []any
+	}{
+		{"contact.reply.subject", []any{defaultBranding.Name}},
+		{"contact.reply.greeting", nil},
+		{"contact.sent_text", nil},
+		{"contact.reply.attachment", nil},
+	} {
+		text, err := translate(msg.Lang, message.key, message.args...)
+		if err != nil {
+			return err
+		}
+		texts = append(texts, text)
+	}
+	email.Subject = texts[0]
+	email.PlainTextBody = texts[1] + "\n\n" + texts[2] + "\n\n"
+	if len(email.Attachments) > 0 {
+		email.PlainTextBody += texts[3] + "\n\n"
+	}
+	email.PlainTextBody += defaultBranding.Name + "\n" + defaultBranding.URL + localizedPath(msg.Lang, "/") + "\n"
+
+	// HTML body
+	buf := &strings.Builder{}
+	err = contactReplyEmailTmpl.ExecuteTemplate(buf, "email", map[string]any{
+		"Lang":          msg.Lang,
+		"Branding":      defaultBranding,
+		"HasAttachment": len(email.Attachments) > 0,
+	})
+	if err != nil {
+		return err
+	}
+	email.HTMLBody = buf.String()
+
+	return s.emailer(email)
+}
+
+// Pretends that the message was sent so that bots don't adapt, the submission is only counted.
+func (s *site) rejectContactForm(w http.ResponseWriter, r *http.Request, visitorHash, reason string) {
+	s.storeRejectedSubmission(visitorHash, reason)
+	l, _ := splitLangPath(r.URL.Path)
+	http.Redirect(w, r, localizedPath(l, "/contact/sent"), http.StatusSeeOther)
+}
+
+func (s *site) storeRejectedSubmission(visitorHash, reason string) {
+	err := s.db.StoreRejectedSubmission(&rejectedSubmission{
+		ID:          newID(16),
+		CreatedAt:   time.Now(),
+		Form:        contactFormName,
+		Reason:      reason,
+		VisitorHash: visitorHash,
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Renders the contact page on each request since the form holds a new token every time.
+func (s *site) serveContactPage(w http.ResponseWriter, r *http.Request) {
+	s.respondContactForm(w, r, &contactForm{}, http.StatusOK)
+}
+
+// Renders the contact page with the submitted values and validation errors, and a new form token.
+func (s *site) respondContactForm(w http.ResponseWriter, r *http.Request, form *contactForm, status int) {
+	l, path := splitLangPath(r.URL.Path)
+	robots := robotsIndex
+	if status != http.StatusOK {
+		robots = robotsNoIndex
+	}
+	form.Token = newFormToken([]byte(s.config.FormSecret), contactFormName, time.Now())
+	p := page{Path: path, Template: "contact.gohtml", TitleKey: "contact.title", Robots: robots, Data: form}
+	state := s.currentState()
+	buf := &bytes.Buffer{}
+	err := executePage(buf, state.contactTmpl, p, l, state.resume)
+	if err != nil {
+		log.Println(err)
+		respondErrorPage(w, r, http.StatusInternalServerError, "error.fatal")
+		return
+	}
+	w.Header().Set("Content-Type", htmlContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, err = buf.WriteTo(w)
+	if err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Opens a new database in a temporary directory, closed at the end of the test.
+func newTestBoltDB(t *testing.T) *boltDB {
+	t.Helper()
+	t.Setenv("DB_DIR_PATH", t.TempDir())
+	db := newBoltDB()
+	t.Cleanup(func() { db.close() })
+	return db
+}
+
+func TestSendContactReply(t *testing.T) {
+	db := newTestBoltDB(t)
+	sent := []*Email{}
+	s := &site{
+		config:  &Config{SMTPSender: "Website <website@example.com>"},
+		db:      db,
+		emailer: func(email *Email) error { sent = append(sent, email); return nil },
+	}
+	s.state.Store(&siteState{files: snapshot{}})
+
+	now := time.Now()
+	for i, msg := range []*contactMessage{
+		{ID: "1", CreatedAt: now, Lang: french, Name: "Visit https://spam.example", Email: "jane@example.com", Message: "Buy now"},
+		{ID: "2", CreatedAt: now, Lang: french, Name: "Jane", Email: "JANE@example.com", Message: "Hello again"},
+	} {
+		err := db.StoreContactMessage(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = s.sendContactReply(msg, "contact_reply:"+msg.ID)
+		if err != nil {
+			t.Fatalf("message %d: %s", i, err)
+		}
+	}
+
+	if len(sent) != 1 {
+		t.Fatalf("got %d replies, want 1 (replies to the same address are rate limited)", len(sent))
+	}
+	reply := sent[0]
+	if len(reply.To) != 1 || reply.To[0] != "jane@example.com" {
+		t.Errorf("got recipients %q", reply.To)
+	}
+	for _, body := range []string{reply.Subject, reply.PlainTextBody, reply.HTMLBody} {
+		for _, typed := range []string{"spam.example", "Buy now"} {
+			if strings.Contains(body, typed) {
+				t.Errorf("reply contains %q typed by the sender:\n%s", typed, body)
+			}
+		}
+	}
+	if !strings.HasPrefix(reply.PlainTextBody, "Bonjour,\n") {
+		t.Errorf("got plain text body %q, want a French reply", reply.PlainTextBody)
+	}
+}
+
+func TestServeContactPage(t *testing.T) {
+	contactTmpl, err := parseTemplates("contact.gohtml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	brokenTmpl := template.Must(template.New("broken").Parse(`{{ define "page_layout" }}partial page{{ call .Lang }}{{ end }}`))
+
+	tests := map[string]struct {
+		tmpl       *template.Template
+		wantStatus int
+		want       string
+	}{
+		"rendered":       {contactTmpl, http.StatusOK, `name="token"`},
+		"template error": {brokenTmpl, http.StatusInternalServerError, "fatal error"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &site{config: &Config{FormSecret: "0123456789abcdef"}}
+			s.state.Store(&siteState{files: snapshot{}, resume: resumeData, contactTmpl: test.tmpl})
+			w := httptest.NewRecorder()
+			s.serveContactPage(w, httptest.NewRequest(http.MethodGet, "/contact", nil))
+			if w.Code != test.wantStatus {
+				t.Fatalf("got status %d, want %d", w.Code, test.wantStatus)
+			}
+			if body := w.Body.String(); !strings.Contains(body, test.want) || strings.Contains(body, "partial page") {
+				t.Fatalf("got body:\n%s", body)
+			}
+		})
+	}
+}
+
+// A form submitted twice (ex: double click) must only be forwarded to the admin once.
+func TestHandleContactFormSubmittedTwice(t *testing.T) {
+	outbox, sent := newTestOutbox(t, nil)
+	s := &site{
+		config:  &Config{SMTPSender: "website@example.com", AdminEmailAddr: "admin@example.com", FormSecret: "0123456789abcdef"},
+		db:      newTestBoltDB(t),
+		emailer: outbox.enqueue,
+	}
+	s.state.Store(&siteState{files: snapshot{}})
+
+	form := url.Values{
+		"name":    {"Jane"},
+		"email":   {"jane@example.com"},
+		"message": {"Hello, I would like to talk about a project."},
+		"token":   {newFormToken([]byte(s.config.FormSecret), contactFormName, time.Now().Add(-time.Minute))},
+	}
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		s.handleContactForm(w, r)
+		if w.Code != http.StatusSeeOther {
+			t.Fatalf("got status %d, want %d", w.Code, http.StatusSeeOther)
+		}
+	}
+	outbox.deliverPending(true)
+
+	recipients := []string{}
+	for _, email := range *sent {
+		recipients = append(recipients, email.To...)
+	}
+	if fmt.Sprint(recipients) != "[admin@example.com jane@example.com]" {
+		t.Fatalf("got emails to %q, want one to the admin and one reply", recipients)
+	}
+}
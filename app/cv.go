@@ -1,11 +1,9 @@
 package app
 
 import (
-	"bytes"
 	"embed"
 	"fmt"
 	"io"
-	"net/http"
 	"strings"
 	"time"
 
@@ -15,70 +13,169 @@ import (
 // Resume data
 
 type resume struct {
-	Name     string
-	TagLine  map[lang]string
-	PDFTitle string
+	Name     string          `json:"name"`
+	TagLine  map[lang]string `json:"tag_line"`
+	PDFTitle string          `json:"pdf_title"`
 	// Experiences
-	ExperiencesTitle          map[lang]string
-	Experiences               []experience
-	ExperienceDurationKey     map[lang]string
-	ExperienceCompanyKey      map[lang]string
-	ExperienceLocationKey     map[lang]string
-	ExperienceTechnologiesKey map[lang]string
-	ExperienceDescriptionKey  map[lang]string
-	ExperienceNow             map[lang]string
-	ExperienceMonths          map[lang]string
+	ExperiencesTitle          map[lang]string `json:"experiences_title"`
+	Experiences               []experience    `json:"experiences"`
+	ExperienceDurationKey     map[lang]string `json:"experience_duration_key"`
+	ExperienceCompanyKey      map[lang]string `json:"experience_company_key"`
+	ExperienceLocationKey     map[lang]string `json:"experience_location_key"`
+	ExperienceTechnologiesKey map[lang]string `json:"experience_technologies_key"`
+	ExperienceDescriptionKey  map[lang]string `json:"experience_description_key"`
+	ExperienceNow             map[lang]string `json:"experience_now"`
 	// Skills
-	SkillsTitle map[lang]string
-	Skills      []skill
+	SkillsTitle map[lang]string `json:"skills_title"`
+	Skills      []skill         `json:"skills"`
 	// Languages
-	LanguagesTitle map[lang]string
-	Languages      []language
+	LanguagesTitle map[lang]string `json:"languages_title"`
+	Languages      []language      `json:"languages"`
 	// External links
-	ExternalLinksTitle map[lang]string
-	ExternalLinks      []externalLink
+	ExternalLinksTitle map[lang]string `json:"external_links_title"`
+	ExternalLinks      []externalLink  `json:"external_links"`
 	// Contact
-	ContactLinksTitle map[lang]string
-	ContactLinks      []contactLink
+	ContactLinksTitle map[lang]string `json:"contact_links_title"`
+	ContactLinks      []contactLink   `json:"contact_links"`
 	// Source code
-	SourceCodeText map[lang]string
-	SourceCodeURL  string
-	GeneratedAt    map[lang]string
+	SourceCodeText map[lang]string `json:"source_code_text"`
+	SourceCodeURL  string          `json:"source_code_url"`
+	GeneratedAt    map[lang]string `json:"generated_at"`
+	// Tailored versions of the résumé
+	Variants []resumeVariant `json:"variants"`
+	Variant  string          `json:"-"` // name of the variant this résumé was filtered for (if any)
+	// Reference time for ongoing experiences (set when rendering)
+	RenderTime time.Time `json:"-"`
 }
 
 type experience struct {
-	Title          map[lang]string
-	Company        string
-	From           time.Time
-	To             time.Time
-	Duration       string
-	Description    map[lang]string
-	Location       string
-	SkillsAndTools []string
+	Title          map[lang]string `json:"title"`
+	Company        string          `json:"company"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	Duration       string          `json:"-"`
+	Description    map[lang]string `json:"description"`
+	Location       string          `json:"location"`
+	SkillsAndTools []string        `json:"skills_and_tools"`
+	Tags           []string        `json:"tags"`
 }
 
-// Returns the number of months from the start to the end of the work experience.
-func (exp *experience) Months() int { return int(exp.To.Sub(exp.From).Hours() / (24 * 30)) }
+// Returns the start and end months of the experience and its duration (ex: "January 2022 - October 2022 (10 mos)").
+func (content resume) ExperiencePeriod(exp experience, l lang) string {
+	to := content.ExperienceNow[l]
+	if !exp.To.IsZero() {
+		to = formatMonthYear(l, exp.To)
+	}
+	return formatMonthYear(l, exp.From) + " - " + to + " (" + content.ExperienceDuration(exp, l) + ")"
+}
+
+// Returns the duration of the experience (ex: "10 mos").
+func (content resume) ExperienceDuration(exp experience, l lang) string {
+	return formatMonths(content.experienceMonths(exp), l)
+}
+
+// Returns the number of calendar months covered by the experience, including its start and end months.
+// Ongoing experiences end at the render time of the résumé.
+func (content resume) experienceMonths(exp experience) int {
+	return len(content.monthsCovered(exp))
+}
+
+// Returns the calendar months covered by the experience (as "year*12 + month" indexes).
+func (content resume) monthsCovered(exp experience) map[int]bool {
+	to := exp.To
+	if to.IsZero() {
+		to = content.RenderTime
+	}
+	out := map[int]bool{}
+	for i := monthIndex(exp.From); i <= monthIndex(to); i++ {
+		out[i] = true
+	}
+	return out
+}
+
+func monthIndex(t time.Time) int { return t.Year()*12 + int(t.Month()) - 1 }
+
+// Returns the total experience with the given tool (ex: "1 yr 3 mos"),
+// or an empty string if no experience mentions it.
+func (content resume) ToolDuration(tool string, l lang) string {
+	if months := content.toolMonths(tool); months > 0 {
+		return formatMonths(months, l)
+	}
+	return ""
+}
+
+// Returns the total experience with any of the tools of the skill (ex: "1 yr 3 mos"),
+// or an empty string if no experience mentions them.
+func (content resume) SkillDuration(s skill, l lang) string {
+	if months := content.skillMonths(s); months > 0 {
+		return formatMonths(months, l)
+	}
+	return ""
+}
+
+// Returns the number of months of experience with the given tool,
+// overlapping experiences are only counted once.
+func (content resume) toolMonths(tool string) int {
+	months := map[int]bool{}
+	for _, exp := range content.Experiences {
+		for _, expTool := range exp.SkillsAndTools {
+			if !strings.EqualFold(expTool, tool) {
+				continue
+			}
+			for i := range content.monthsCovered(exp) {
+				months[i] = true
+			}
+		}
+	}
+	return len(months)
+}
+
+// Returns the number of months of experience with any of the tools of the skill,
+// overlapping experiences are only counted once.
+func (content resume) skillMonths(s skill) int {
+	months := map[int]bool{}
+	for _, exp := range content.Experiences {
+		if !sharesTool(exp.SkillsAndTools, s.Tools) {
+			continue
+		}
+		for i := range content.monthsCovered(exp) {
+			months[i] = true
+		}
+	}
+	return len(months)
+}
+
+func sharesTool(a, b []string) bool {
+	for _, toolA := range a {
+		for _, toolB := range b {
+			if strings.EqualFold(toolA, toolB) {
+				return true
+			}
+		}
+	}
+	return false
+}
 
 type skill struct {
-	Title map[lang]string
-	Tools []string
+	Title map[lang]string `json:"title"`
+	Tools []string        `json:"tools"`
+	Tags  []string        `json:"tags"`
 }
 
 type language struct {
-	Flag  string
-	Name  map[lang]string
-	Level map[lang]string
+	Flag  string          `json:"flag"`
+	Name  map[lang]string `json:"name"`
+	Level map[lang]string `json:"level"`
 }
 
 type externalLink struct {
-	Label map[lang]string
-	URL   string
+	Label map[lang]string `json:"label"`
+	URL   string          `json:"url"`
 }
 
 type contactLink struct {
-	Label map[lang]string
-	URL   string
+	Label map[lang]string `json:"label"`
+	URL   string          `json:"url"`
 }
 
 var resumeData = resume{
@@ -95,7 +192,6 @@ var resumeData = resume{
 	ExperienceTechnologiesKey: map[lang]string{english: "Technologies", french: "Technologies"},
 	ExperienceDescriptionKey:  map[lang]string{english: "Description", french: "Description"},
 	ExperienceNow:             map[lang]string{english: "now", french: "maintenant"},
-	ExperienceMonths:          map[lang]string{english: "months", french: "mois"},
 	Experiences: []experience{
 		{
 			Title: map[lang]string{
@@ -110,7 +206,8 @@ var resumeData = resume{
 				english: "Working on developing and maintaining the DNS, domains and transactional email products.",
 				french:  "Développement et maintenance des produits DNS, nom de domaines et email transactionnel.",
 			},
-			SkillsAndTools: []string{"DNS", "SMTP", "Go"},
+			SkillsAndTools: []string{"DNS", "SMTP", "Golang"},
+			Tags:           []string{"devops", "backend"},
 		},
 		{
 			Title: map[lang]string{
@@ -126,6 +223,7 @@ var resumeData = resume{
 				french:  "Initiation et formation au fondamentaux du développement web auprès de (ex-) détenus et de jeunes en difficulté.",
 			},
 			SkillsAndTools: []string{"HTTP", "HTML", "CSS", "JavaScript"},
+			Tags:           []string{"teaching", "frontend"},
 		},
 		{
 			Title: map[lang]string{
@@ -141,6 +239,7 @@ var resumeData = resume{
 				french:  "Développement d'une nouvelle solution de live streaming de vidéo basé sur DASH et HLS.",
 			},
 			SkillsAndTools: []string{"Golang", "Docker", "Kubernetes", "PostgreSQL"},
+			Tags:           []string{"backend", "devops"},
 		},
 		{
 			Title: map[lang]string{
@@ -156,6 +255,7 @@ var resumeData = resume{
 				french:  "Développement front et back pour plusieurs PMEs",
 			},
 			SkillsAndTools: []string{"Golang", "TypeScript", "Svelte / Vue / React"},
+			Tags:           []string{"backend", "frontend"},
 		},
 		{
 			Title: map[lang]string{
@@ -171,6 +271,7 @@ var resumeData = resume{
 				french:  "Gestion du projet d'expansion et des opérations de notre application web dans 5 nouveaux pays européens.",
 			},
 			SkillsAndTools: []string{"Ruby on Rails", "GCP"},
+			Tags:           []string{"management", "teaching"},
 		},
 	},
 	SkillsTitle: map[lang]string{english: "Skills", french: "Compétences"},
@@ -178,22 +279,27 @@ var resumeData = resume{
 		{
 			Title: map[lang]string{english: "Programming languages", french: "Langages de programmation"},
 			Tools: []string{"Golang", "JavaScript / Typescript"},
+			Tags:  []string{"backend", "devops", "teaching"},
 		},
 		{
 			Title: map[lang]string{english: "Website development", french: "Développement de site web"},
 			Tools: []string{"HTTP", "HTML", "CSS", "JS", "Svelte / Vue / React", "A11y"},
+			Tags:  []string{"frontend", "teaching"},
 		},
 		{
 			Title: map[lang]string{english: "DevOps & CI/CD", french: "DevOps & CI/CD"},
 			Tools: []string{"Linux", "Bash", "Ansible", "Gitlab CI / Github Actions", "Docker / Podman", "Kubernetes"},
+			Tags:  []string{"devops", "backend"},
 		},
 		{
 			Title: map[lang]string{english: "Database", french: "Bases de données"},
 			Tools: []string{"PostgreSQL", "MongoDB", "SQLite", "BoltDB"},
+			Tags:  []string{"backend", "devops"},
 		},
 		{
 			Title: map[lang]string{english: "SE Practices", french: "Pratiques de développement logiciel"},
 			Tools: []string{"TDD / BDD", "Clean architecture", "Pair / mob programming"},
+			Tags:  []string{"backend", "teaching"},
 		},
 	},
 	LanguagesTitle: map[lang]string{english: "Languages", french: "Langues"},
@@ -247,219 +353,262 @@ var resumeData = resume{
 	},
 	SourceCodeURL: "https://github.com/ejuju/personal_website",
 	GeneratedAt:   map[lang]string{english: "PDF generated on ", french: "PDF généré le "},
-}
-
-func generateAndServeResumeFile(content resume, l lang) http.HandlerFunc {
-	buf := &bytes.Buffer{}
-	err := generateResumePDF(buf, content, l)
-	if err != nil {
-		panic(err)
-	}
-	return func(w http.ResponseWriter, r *http.Request) { w.Write(buf.Bytes()) }
+	Variants: []resumeVariant{
+		{
+			Name: "devops",
+			Tags: []string{"devops"},
+			TagLine: map[lang]string{
+				english: "Self-taught DevOps engineer,\nspecialised in running and automating web infrastructure.",
+				french:  "Ingénieur DevOps auto-didacte,\nspécialisé dans l'exploitation et l'automatisation d'infrastructures web.",
+			},
+		},
+		{
+			Name:        "backend",
+			Tags:        []string{"backend"},
+			OrderByTags: true,
+			TagLine: map[lang]string{
+				english: "Passionate self-taught software engineer,\nspecialised in backend web development with Go.",
+				french:  "Développeur auto-didacte passionné,\nspécialisé en développement web backend avec Go.",
+			},
+		},
+		{
+			Name:        "teaching",
+			Tags:        []string{"teaching", "management"},
+			OrderByTags: true,
+			TagLine: map[lang]string{
+				english: "Web development tutor,\nexperienced in teaching programming to beginners from all backgrounds.",
+				french:  "Formateur en développement web,\nexpérimenté dans l'initiation à la programmation de publics variés.",
+			},
+		},
+	},
 }
 
 // PDF generation
 
 const a4WidthPt, a4HeightPt = 595.28, 842.89
 
-var (
-	titleFontSize  = 24.0
-	bigFontSize    = 16.0
-	normalFontSize = 9.5
-	marginTopSize  = 50.0
-	marginSideSize = 50.0 // left and right margins
-	textColor      = [3]int{0, 0, 0}
-	textDimColor   = [3]int{50, 50, 50}
-	midColor       = [3]int{127, 127, 127}
-)
+// resumePDF wraps the PDF document with the theme used to style it.
+type resumePDF struct {
+	*fpdf.Fpdf
+	theme *pdfTheme
+}
 
-func generateResumePDF(w io.Writer, content resume, l lang) error {
-	pdf := fpdf.New("P", "pt", "A4", "")
+func generateResumePDF(w io.Writer, content resume, l lang, theme *pdfTheme) error {
+	pdf := renderResumePDF(content, l, theme)
 
-	// Set metadata
-	pdf.SetCreationDate(time.Now())
+	// Scale down single-page themes until the résumé fits on one page
+	scaled := *theme
+	for theme.SinglePage && pdf.PageCount() > 1 && scaled.NormalFontSize > 6 {
+		scaled = scaled.scaled(0.95)
+		pdf = renderResumePDF(content, l, &scaled)
+	}
+
+	return pdf.Output(w)
+}
+
+func renderResumePDF(content resume, l lang, theme *pdfTheme) *resumePDF {
+	pdf := &resumePDF{Fpdf: fpdf.New("P", "pt", "A4", ""), theme: theme}
+
+	// Set metadata (the output only depends on the content, so that identical input yields identical bytes)
+	pdf.SetCatalogSort(true)
+	pdf.SetCreationDate(content.RenderTime)
+	pdf.SetModificationDate(content.RenderTime)
 	pdf.SetAuthor(content.Name, true)
 	pdf.SetLang(string(l))
 	pdf.SetTitle(content.PDFTitle, true)
 
-	// Setup font
-	font := "Roboto"
-	pdf.AddUTF8FontFromBytes(font, "", mustReadEmbeddedFile(staticFilesFS, "static/"+font+"-Regular.ttf"))
-	pdf.AddUTF8FontFromBytes(font, "B", mustReadEmbeddedFile(staticFilesFS, "static/"+font+"-Bold.ttf"))
-	pdf.SetFont(font, "", normalFontSize)
+	// Setup fonts
+	for _, font := range []string{theme.BodyFont, theme.HeadingFont} {
+		pdf.AddUTF8FontFromBytes(font, "", mustReadEmbeddedFile(staticFilesFS, "static/"+font+"-Regular.ttf"))
+		pdf.AddUTF8FontFromBytes(font, "B", mustReadEmbeddedFile(staticFilesFS, "static/"+font+"-Bold.ttf"))
+	}
+	pdf.SetFont(theme.BodyFont, "", theme.NormalFontSize)
 
 	// Setup default styles
-	pdf.SetTopMargin(marginTopSize)
-	pdf.SetLeftMargin(marginSideSize)
-	pdf.SetRightMargin(marginSideSize)
-	pdf.SetTextColor(rgb(textColor))
-	pdf.SetFillColor(rgb(textDimColor))
+	pdf.SetTopMargin(theme.MarginTop)
+	pdf.SetLeftMargin(theme.MarginSide)
+	pdf.SetRightMargin(theme.MarginSide)
+	pdf.SetTextColor(rgb(theme.TextColor))
+	pdf.SetFillColor(rgb(theme.TextDimColor))
 
 	// Setup footer callback
 	pdf.AliasNbPages("{max_page}")
 	pdf.SetFooterFuncLpi(func(isLastPage bool) {
 		txt := fmt.Sprintf("Page %d/{max_page}", pdf.PageCount())
-		setTempTextColor(pdf, midColor, func() {
-			pdf.Text(marginSideSize+3, a4HeightPt-4*normalFontSize, txt)
+		pdf.setTempTextColor(theme.MidColor, func() {
+			pdf.Text(theme.MarginSide+3, a4HeightPt-4*theme.NormalFontSize, txt)
 
 			if !isLastPage {
 				return
 			}
-			pdf.Ln(8 * normalFontSize)
-			pdf.Write(normalFontSize+4, content.SourceCodeText[l]+"\n")
-			setTempFontStyle(pdf, "U", func() { addClickableURL(pdf, content.SourceCodeURL) })
-			pdf.Write(normalFontSize+4, "\n"+content.GeneratedAt[l]+time.Now().Format("02/01/2006 (15:04:05)"))
+			pdf.Ln(theme.FooterSpacing * theme.NormalFontSize)
+			pdf.Write(pdf.lineHeight(), content.SourceCodeText[l]+"\n")
+			pdf.setTempFontStyle("U", func() { pdf.addClickableURL(content.SourceCodeURL) })
+			pdf.Write(pdf.lineHeight(), "\n"+content.GeneratedAt[l]+formatDate(l, content.RenderTime))
 		})
 	})
 
-	// Create page 1
-	pdf.AddPage()
+	theme.Layout(pdf, content, l)
+	return pdf
+}
 
-	// Add title
-	pdf.Bookmark(defaultBranding.Name, 0, -1)
-	setTempFontSize(pdf, titleFontSize, func() {
-		setTempFontStyle(pdf, "B", func() {
-			pdf.MultiCell(0, titleFontSize, defaultBranding.Name, "", "C", false)
-		})
+func (pdf *resumePDF) lineHeight() float64 { return pdf.theme.NormalFontSize + pdf.theme.LineSpacing }
+
+// Adds the name and tag line centered on top of the page, followed by an horizontal line.
+func (pdf *resumePDF) addTitle(content resume, l lang) {
+	pdf.Bookmark(content.Name, 0, -1)
+	pdf.setTempHeadingFont(pdf.theme.TitleFontSize, func() {
+		pdf.MultiCell(0, pdf.theme.TitleFontSize, content.Name, "", "C", false)
 	})
 
-	// Add sub-title
-	pdf.Ln(2 * normalFontSize)
-	setTempTextColor(pdf, textDimColor, func() {
-		pdf.MultiCell(0, normalFontSize+4, content.TagLine[l], "", "C", false)
+	pdf.Ln(pdf.theme.TitleSpacing * pdf.theme.NormalFontSize)
+	pdf.setTempTextColor(pdf.theme.TextDimColor, func() {
+		pdf.MultiCell(0, pdf.lineHeight(), content.TagLine[l], "", "C", false)
 	})
 
-	// Add horizontal line below sub-title
-	pdf.Ln(3 * normalFontSize)
+	pdf.Ln(1.5 * pdf.theme.TitleSpacing * pdf.theme.NormalFontSize)
 	left, _, right, _ := pdf.GetMargins()
-	pdf.Rect(left, pdf.GetY(), a4WidthPt-2*right, 0.5, "F")
+	pdf.Rect(left, pdf.GetY(), a4WidthPt-left-right, 0.5, "F")
+	pdf.Ln(1.5 * pdf.theme.TitleSpacing * pdf.theme.NormalFontSize)
+}
 
-	// Add experiences
-	pdf.Ln(3 * normalFontSize)
-	addSection(pdf, content.ExperiencesTitle[l], func() {
+func (pdf *resumePDF) addExperiences(content resume, l lang, keyCellWidth float64) {
+	normalFontSize := pdf.theme.NormalFontSize
+	pdf.addSection(content.ExperiencesTitle[l], func() {
 		for _, exp := range content.Experiences {
 			pdf.Bookmark(fmt.Sprintf("%s (%s)", exp.Title[l], exp.Company), 2, -1)
 			pdf.Ln(2 * normalFontSize)
 
-			setTempFontStyle(pdf, "B", func() {
-				pdf.MultiCell(0, normalFontSize+4, exp.Title[l], "", "", false)
+			pdf.setTempFontStyle("B", func() {
+				pdf.MultiCell(0, pdf.lineHeight(), exp.Title[l], "", "", false)
 			})
 
 			pdf.Ln(0.5 * normalFontSize)
-			fromStr := exp.From.Format("01/2006")
-			toStr := content.ExperienceNow[l]
-			if !exp.To.IsZero() {
-				toStr = exp.To.Format("01/2006")
-			}
-			dur := fromStr + " - " + toStr
-			if !exp.To.IsZero() {
-				dur += fmt.Sprintf(" (%d %s)", exp.Months(), content.ExperienceMonths[l])
-			}
-			addKV(pdf, 88, content.ExperienceDurationKey[l], fromStr+" - "+toStr, midColor, textDimColor, "", "")
+			pdf.addKV(keyCellWidth, content.ExperienceDurationKey[l], content.ExperiencePeriod(exp, l), pdf.theme.MidColor, pdf.theme.TextDimColor, "", "")
 			pdf.Ln(0.125 * normalFontSize)
-			addKV(pdf, 88, content.ExperienceCompanyKey[l], exp.Company, midColor, textDimColor, "", "")
+			pdf.addKV(keyCellWidth, content.ExperienceCompanyKey[l], exp.Company, pdf.theme.MidColor, pdf.theme.TextDimColor, "", "")
 			pdf.Ln(0.125 * normalFontSize)
-			addKV(pdf, 88, content.ExperienceLocationKey[l], exp.Location, midColor, textDimColor, "", "")
+			pdf.addKV(keyCellWidth, content.ExperienceLocationKey[l], exp.Location, pdf.theme.MidColor, pdf.theme.TextDimColor, "", "")
 			pdf.Ln(0.125 * normalFontSize)
-			addKV(pdf, 88, content.ExperienceTechnologiesKey[l], strings.Join(exp.SkillsAndTools, ", "), midColor, textDimColor, "", "")
+			pdf.addKV(keyCellWidth, content.ExperienceTechnologiesKey[l], strings.Join(exp.SkillsAndTools, ", "), pdf.theme.MidColor, pdf.theme.TextDimColor, "", "")
 			pdf.Ln(0.125 * normalFontSize)
-			addKV(pdf, 88, content.ExperienceDescriptionKey[l], exp.Description[l], midColor, textDimColor, "", "")
+			pdf.addKV(keyCellWidth, content.ExperienceDescriptionKey[l], exp.Description[l], pdf.theme.MidColor, pdf.theme.TextDimColor, "", "")
 		}
-
-		pdf.AddPage() // move on to page 2 for other sections
 	})
+}
 
-	// Add skills
-	addSection(pdf, content.SkillsTitle[l], func() {
+func (pdf *resumePDF) addSkills(content resume, l lang) {
+	normalFontSize := pdf.theme.NormalFontSize
+	pdf.addSection(content.SkillsTitle[l], func() {
 		for _, skill := range content.Skills {
 			pdf.Bookmark(skill.Title[l], 2, -1)
 
-			setTempFontStyle(pdf, "B", func() {
+			pdf.setTempFontStyle("B", func() {
 				pdf.Ln(1 * normalFontSize)
-				pdf.MultiCell(0, normalFontSize+4, skill.Title[l], "", "", false)
+				title := skill.Title[l]
+				if dur := content.SkillDuration(skill, l); dur != "" {
+					title += " (" + dur + ")"
+				}
+				pdf.MultiCell(0, pdf.lineHeight(), title, "", "", false)
 			})
 
-			setTempTextColor(pdf, textDimColor, func() {
+			pdf.setTempTextColor(pdf.theme.TextDimColor, func() {
 				pdf.Ln(0.25 * normalFontSize)
-				pdf.MultiCell(0, normalFontSize+4, strings.Join(skill.Tools, ", "), "", "", false)
+				pdf.MultiCell(0, pdf.lineHeight(), strings.Join(skill.Tools, ", "), "", "", false)
 			})
 		}
 	})
+}
 
-	// Add languages
-	pdf.Ln(3 * normalFontSize)
-	addSection(pdf, content.LanguagesTitle[l], func() {
+func (pdf *resumePDF) addLanguages(content resume, l lang, keyCellWidth float64) {
+	normalFontSize := pdf.theme.NormalFontSize
+	pdf.addSection(content.LanguagesTitle[l], func() {
 		pdf.Ln(0.75 * normalFontSize)
 		for _, lang := range content.Languages {
 			pdf.Bookmark(lang.Name[l], 2, -1)
 
 			pdf.Ln(0.25 * normalFontSize)
-			addKV(pdf, 66, lang.Name[l], lang.Level[l], textDimColor, midColor, "B", "")
+			pdf.addKV(keyCellWidth, lang.Name[l], lang.Level[l], pdf.theme.TextDimColor, pdf.theme.MidColor, "B", "")
 		}
 	})
+}
 
-	// Add links
-	pdf.Ln(3 * normalFontSize)
-	addSection(pdf, content.ExternalLinksTitle[l], func() {
+func (pdf *resumePDF) addExternalLinks(content resume, l lang) {
+	pdf.addLinkRows(content.ExternalLinksTitle[l], toContactLinks(content.ExternalLinks), l, 106)
+}
+
+// Adds links with their label and URL on the same line.
+func (pdf *resumePDF) addLinkRows(heading string, links []contactLink, l lang, labelCellWidth float64) {
+	normalFontSize := pdf.theme.NormalFontSize
+	pdf.addSection(heading, func() {
 		pdf.Ln(0.25 * normalFontSize)
-		for _, link := range content.ExternalLinks {
+		for _, link := range links {
 			pdf.Bookmark(link.Label[l], 2, -1)
 
 			pdf.Ln(0.75 * normalFontSize)
-			setTempTextColor(pdf, textDimColor, func() {
-				setTempFontStyle(pdf, "B", func() { pdf.CellFormat(106, normalFontSize+4, link.Label[l]+" ", "", 0, "", false, 0, "") })
-				setTempFontStyle(pdf, "U", func() { addClickableURL(pdf, link.URL) })
+			pdf.setTempTextColor(pdf.theme.TextDimColor, func() {
+				pdf.setTempFontStyle("B", func() { pdf.CellFormat(labelCellWidth, pdf.lineHeight(), link.Label[l]+" ", "", 0, "", false, 0, "") })
+				pdf.setTempFontStyle("U", func() { pdf.addClickableURL(link.URL) })
 			})
 		}
 	})
+}
 
-	// Add contact section
-	pdf.Ln(3 * normalFontSize)
-	addSection(pdf, content.ContactLinksTitle[l], func() {
-		for _, link := range content.ContactLinks {
+// Adds links with their label on a line and the URL below (used for narrow columns).
+func (pdf *resumePDF) addStackedLinks(heading string, links []contactLink, l lang) {
+	pdf.addSection(heading, func() {
+		for _, link := range links {
 			pdf.Bookmark(link.Label[l], 2, -1)
 
-			pdf.Ln(1 * normalFontSize)
-			setTempFontStyle(pdf, "B", func() {
-				pdf.CellFormat(0, normalFontSize+4, link.Label[l], "", 1, "", false, 0, "")
+			pdf.Ln(1 * pdf.theme.NormalFontSize)
+			pdf.setTempFontStyle("B", func() {
+				pdf.CellFormat(0, pdf.lineHeight(), link.Label[l], "", 1, "", false, 0, "")
 			})
-			setTempFontStyle(pdf, "U", func() {
-				setTempTextColor(pdf, textDimColor, func() {
-					addClickableURL(pdf, link.URL)
+			pdf.setTempFontStyle("U", func() {
+				pdf.setTempTextColor(pdf.theme.TextDimColor, func() {
+					pdf.addClickableURL(link.URL)
 				})
 			})
 		}
 	})
+}
 
-	return pdf.Output(w)
+func (pdf *resumePDF) addContactLinks(content resume, l lang) {
+	pdf.addStackedLinks(content.ContactLinksTitle[l], content.ContactLinks, l)
+}
+
+func toContactLinks(links []externalLink) []contactLink {
+	out := make([]contactLink, 0, len(links))
+	for _, link := range links {
+		out = append(out, contactLink(link))
+	}
+	return out
 }
 
 func rgb(clr [3]int) (r, g, b int) { return clr[0], clr[1], clr[2] }
 
-func addSection(pdf *fpdf.Fpdf, heading string, cb func()) {
+func (pdf *resumePDF) addSection(heading string, cb func()) {
 	pdf.Bookmark(heading, 1, -1)
-	pdf.SetFontSize(bigFontSize)
-	pdf.SetFontStyle("B")
-	pdf.MultiCell(0, bigFontSize+4, heading, "", "", false)
-	pdf.SetFontStyle("")
-	pdf.SetFontSize(normalFontSize)
+	pdf.setTempHeadingFont(pdf.theme.BigFontSize, func() {
+		pdf.MultiCell(0, pdf.theme.BigFontSize+pdf.theme.LineSpacing, heading, "", "", false)
+	})
 	cb()
 }
 
-func addKV(pdf *fpdf.Fpdf, keyCellWidth float64, k, v string, kClr, vClr [3]int, kStyle, vStyle string) {
-	setTempTextColor(pdf, kClr, func() {
-		setTempFontStyle(pdf, kStyle, func() {
-			pdf.CellFormat(keyCellWidth, normalFontSize+4, k, "", 0, "", false, 0, "")
+func (pdf *resumePDF) addKV(keyCellWidth float64, k, v string, kClr, vClr [3]int, kStyle, vStyle string) {
+	pdf.setTempTextColor(kClr, func() {
+		pdf.setTempFontStyle(kStyle, func() {
+			pdf.CellFormat(keyCellWidth, pdf.lineHeight(), k, "", 0, "", false, 0, "")
 		})
 	})
-	setTempTextColor(pdf, vClr, func() {
-		setTempFontStyle(pdf, vStyle, func() {
-			pdf.MultiCell(0, normalFontSize+4, v, "", "", false)
+	pdf.setTempTextColor(vClr, func() {
+		pdf.setTempFontStyle(vStyle, func() {
+			pdf.MultiCell(0, pdf.lineHeight(), v, "", "", false)
 		})
 	})
 }
 
-func addClickableURL(pdf *fpdf.Fpdf, url string) {
+func (pdf *resumePDF) addClickableURL(url string) {
 	urlText := url
 	switch {
 	case strings.HasPrefix(url, "mailto:"):
@@ -467,7 +616,7 @@ func addClickableURL(pdf *fpdf.Fpdf, url string) {
 	case strings.HasPrefix(url, "https://"):
 		urlText = strings.TrimPrefix(url, "https://")
 	}
-	pdf.CellFormat(0, normalFontSize+4, urlText, "", 2, "", false, 0, url)
+	pdf.CellFormat(0, pdf.lineHeight(), urlText, "", 2, "", false, 0, url)
 }
 
 func mustReadEmbeddedFile(fs embed.FS, fname string) []byte {
@@ -486,20 +635,21 @@ func mustParseTime(s string) time.Time {
 	return t
 }
 
-func setTempFontStyle(pdf *fpdf.Fpdf, style string, cb func()) {
+func (pdf *resumePDF) setTempFontStyle(style string, cb func()) {
 	pdf.SetFontStyle(style)
 	defer pdf.SetFontStyle("")
 	cb()
 }
 
-func setTempFontSize(pdf *fpdf.Fpdf, size float64, cb func()) {
-	pdf.SetFontSize(size)
-	defer pdf.SetFontSize(normalFontSize)
+// Switches to the bold heading font of the theme with the given size.
+func (pdf *resumePDF) setTempHeadingFont(size float64, cb func()) {
+	pdf.SetFont(pdf.theme.HeadingFont, "B", size)
+	defer pdf.SetFont(pdf.theme.BodyFont, "", pdf.theme.NormalFontSize)
 	cb()
 }
 
-func setTempTextColor(pdf *fpdf.Fpdf, color [3]int, cb func()) {
+func (pdf *resumePDF) setTempTextColor(color [3]int, cb func()) {
 	pdf.SetTextColor(rgb(color))
-	defer pdf.SetTextColor(rgb(textColor))
+	defer pdf.SetTextColor(rgb(pdf.theme.TextColor))
 	cb()
 }
@@ -0,0 +1,23 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// PDFs are reproducible: rendering the same résumé on the same date must give the same bytes.
+func TestGenerateResumePDF(t *testing.T) {
+	content := resumeData
+	content.RenderTime = time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	for _, theme := range pdfThemes {
+		t.Run(theme.Name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := generateResumePDF(buf, content, english, theme)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "resume_"+theme.Name+".pdf", buf.Bytes())
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Office Open XML (.docx) résumé generation,
+// the document has the same sections and key/value rows as the PDF.
+
+// Width of the key column of key/value rows (in twentieths of a point).
+const docxKeyColumnWidth = 1800
+
+func generateResumeDOCX(w io.Writer, content resume, l lang) error {
+	doc := &docxWriter{}
+
+	// Add title and sub-title
+	doc.bookmark("top", func() { doc.paragraph("Title", doc.run(content.Name, "")) })
+	doc.paragraph("Subtitle", doc.run(content.TagLine[l], ""))
+
+	// Add experiences
+	doc.heading("experiences", content.ExperiencesTitle[l])
+	for _, exp := range content.Experiences {
+		doc.paragraph("Heading2", doc.run(exp.Title[l], ""))
+		doc.kv(content.ExperienceDurationKey[l], doc.run(content.ExperiencePeriod(exp, l), ""))
+		doc.kv(content.ExperienceCompanyKey[l], doc.run(exp.Company, ""))
+		doc.kv(content.ExperienceLocationKey[l], doc.run(exp.Location, ""))
+		doc.kv(content.ExperienceTechnologiesKey[l], doc.run(strings.Join(exp.SkillsAndTools, ", "), ""))
+		doc.kv(content.ExperienceDescriptionKey[l], doc.run(exp.Description[l], ""))
+	}
+	doc.pageBreak() // move on to page 2 for other sections
+
+	// Add skills
+	doc.heading("skills", content.SkillsTitle[l])
+	for _, skill := range content.Skills {
+		doc.paragraph("Heading2", doc.run(skill.Title[l], ""))
+		doc.paragraph("", doc.run(strings.Join(skill.Tools, ", "), "Dim"))
+	}
+
+	// Add languages
+	doc.heading("languages", content.LanguagesTitle[l])
+	for _, language := range content.Languages {
+		doc.kv(language.Name[l], doc.run(language.Level[l], ""))
+	}
+
+	// Add links
+	doc.heading("external_links", content.ExternalLinksTitle[l])
+	for _, link := range content.ExternalLinks {
+		doc.kv(link.Label[l], doc.hyperlink(link.URL))
+	}
+
+	// Add contact section
+	doc.heading("contact", content.ContactLinksTitle[l])
+	for _, link := range content.ContactLinks {
+		doc.kv(link.Label[l], doc.hyperlink(link.URL))
+	}
+
+	// Add source code link
+	doc.paragraph("Footer", doc.run(content.SourceCodeText[l], "")+doc.hyperlink(content.SourceCodeURL))
+
+	return doc.writeTo(w, content, l)
+}
+
+// docxWriter accumulates the body of "word/document.xml"
+// and the relationships it references (hyperlinks).
+type docxWriter struct {
+	body       bytes.Buffer
+	links      []string // hyperlink targets, relationship IDs are derived from their index
+	bookmarkID int
+}
+
+// Returns the XML of a text run, line breaks are converted to "<w:br/>".
+func (doc *docxWriter) run(text, charStyle string) string {
+	out := "<w:r>"
+	if charStyle != "" {
+		out += `<w:rPr><w:rStyle w:val="` + charStyle + `"/></w:rPr>`
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			out += "<w:br/>"
+		}
+		out += `<w:t xml:space="preserve">` + escapeXML(line) + "</w:t>"
+	}
+	return out + "</w:r>"
+}
+
+// Returns the XML of a clickable link, the displayed text omits the "mailto:" and "https://" prefixes.
+func (doc *docxWriter) hyperlink(url string) string {
+	doc.links = append(doc.links, url)
+	text := strings.TrimPrefix(strings.TrimPrefix(url, "mailto:"), "https://")
+	return fmt.Sprintf(`<w:hyperlink r:id="rIdLink%d" w:history="1">%s</w:hyperlink>`, len(doc.links), doc.run(text, "Hyperlink"))
+}
+
+func (doc *docxWriter) paragraph(style string, runs string) {
+	doc.body.WriteString("<w:p>")
+	if style != "" {
+		doc.body.WriteString(`<w:pPr><w:pStyle w:val="` + style + `"/></w:pPr>`)
+	}
+	doc.body.WriteString(runs + "</w:p>")
+}
+
+// Adds a section heading with a bookmark so that it can be linked to and shows up in the navigation pane.
+func (doc *docxWriter) heading(bookmarkName, text string) {
+	doc.bookmark(bookmarkName, func() { doc.paragraph("Heading1", doc.run(text, "")) })
+}
+
+func (doc *docxWriter) bookmark(name string, cb func()) {
+	doc.bookmarkID++
+	fmt.Fprintf(&doc.body, `<w:bookmarkStart w:id="%d" w:name="%s"/>`, doc.bookmarkID, name)
+	cb()
+	fmt.Fprintf(&doc.body, `<w:bookmarkEnd w:id="%d"/>`, doc.bookmarkID)
+}
+
+// Adds a key/value row: the key is shown in a fixed-width column and the value is wrapped next to it.
+func (doc *docxWriter) kv(k string, valueRuns string) {
+	fmt.Fprintf(&doc.body, `<w:p><w:pPr><w:pStyle w:val="KeyValue"/>`+
+		`<w:tabs><w:tab w:val="left" w:pos="%d"/></w:tabs><w:ind w:left="%d" w:hanging="%d"/></w:pPr>`,
+		docxKeyColumnWidth, docxKeyColumnWidth, docxKeyColumnWidth)
+	doc.body.WriteString(doc.run(k, "Key") + "<w:r><w:tab/></w:r>" + valueRuns + "</w:p>")
+}
+
+func (doc *docxWriter) pageBreak() {
+	doc.body.WriteString(`<w:p><w:r><w:br w:type="page"/></w:r></w:p>`)
+}
+
+// Writes the zipped package (with fixed file timestamps so that the output only depends on the content).
+func (doc *docxWriter) writeTo(w io.Writer, content resume, l lang) error {
+	rels := &strings.Builder{}
+	for i, link := range doc.links {
+		fmt.Fprintf(rels, `<Relationship Id="rIdLink%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="%s" TargetMode="External"/>`,
+			i+1, escapeXML(link))
+	}
+
+	zw := zip.NewWriter(w)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", docxContentTypesXML},
+		{"_rels/.rels", docxPackageRelsXML},
+		{"docProps/core.xml", fmt.Sprintf(docxCorePropsXML, escapeXML(content.PDFTitle), escapeXML(content.Name), l)},
+		{"word/_rels/document.xml.rels", fmt.Sprintf(docxDocumentRelsXML, rels.String())},
+		{"word/styles.xml", fmt.Sprintf(docxStylesXML, l)},
+		{"word/document.xml", fmt.Sprintf(docxDocumentXML, doc.body.String())},
+	} {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate})
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, part.content)
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func escapeXML(s string) string {
+	buf := &strings.Builder{}
+	xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
+
+const docxContentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+	`</Types>`
+
+const docxPackageRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+	`</Relationships>`
+
+// Format args: title, author, language.
+const docxCorePropsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+	`<dc:title>%s</dc:title><dc:creator>%s</dc:creator><dc:language>%s</dc:language>` +
+	`</cp:coreProperties>`
+
+// Format args: hyperlink relationships.
+const docxDocumentRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`%s</Relationships>`
+
+// Format args: document body.
+const docxDocumentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<w:body>%s<w:sectPr><w:pgSz w:w="11906" w:h="16838"/>` +
+	`<w:pgMar w:top="1000" w:right="1000" w:bottom="1000" w:left="1000" w:header="0" w:footer="0" w:gutter="0"/></w:sectPr>` +
+	`</w:body></w:document>`
+
+// Styles mirror the PDF: Roboto, black text with dim grey values and mid grey keys.
+// Format args: language.
+const docxStylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Roboto" w:hAnsi="Roboto" w:cs="Roboto"/>` +
+	`<w:sz w:val="19"/><w:lang w:val="%s"/></w:rPr></w:rPrDefault>` +
+	`<w:pPrDefault><w:pPr><w:spacing w:after="60"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+	`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:jc w:val="center"/><w:spacing w:after="240"/></w:pPr><w:rPr><w:b/><w:sz w:val="48"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Subtitle"><w:name w:val="Subtitle"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:jc w:val="center"/><w:spacing w:after="360"/>` +
+	`<w:pBdr><w:bottom w:val="single" w:sz="4" w:space="12" w:color="323232"/></w:pBdr></w:pPr>` +
+	`<w:rPr><w:color w:val="323232"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:keepNext/><w:spacing w:before="360" w:after="120"/><w:outlineLvl w:val="0"/></w:pPr>` +
+	`<w:rPr><w:b/><w:sz w:val="32"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:keepNext/><w:spacing w:before="240" w:after="80"/><w:outlineLvl w:val="1"/></w:pPr>` +
+	`<w:rPr><w:b/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="KeyValue"><w:name w:val="Key value"/><w:basedOn w:val="Normal"/>` +
+	`<w:rPr><w:color w:val="323232"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Footer"><w:name w:val="footer"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:spacing w:before="480"/></w:pPr><w:rPr><w:color w:val="7F7F7F"/></w:rPr></w:style>` +
+	`<w:style w:type="character" w:styleId="Key"><w:name w:val="Key"/><w:rPr><w:color w:val="7F7F7F"/></w:rPr></w:style>` +
+	`<w:style w:type="character" w:styleId="Dim"><w:name w:val="Dim"/><w:rPr><w:color w:val="323232"/></w:rPr></w:style>` +
+	`<w:style w:type="character" w:styleId="Hyperlink"><w:name w:val="Hyperlink"/><w:rPr><w:u w:val="single"/></w:rPr></w:style>` +
+	`</w:styles>`
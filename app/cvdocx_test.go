@@ -0,0 +1,134 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+	"time"
+)
+
+type docxParagraph struct {
+	Style string
+	Text  string
+}
+
+// Returns the paragraphs of "word/document.xml", failing the test if the XML is malformed.
+func readDOCXParagraphs(t *testing.T, raw []byte) []docxParagraph {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	out := []docxParagraph{}
+	var current *docxParagraph
+	inText := false
+	dec := xml.NewDecoder(f)
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return out
+		}
+		if err != nil {
+			t.Fatalf("malformed document.xml: %s", err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "p":
+				current = &docxParagraph{}
+			case "pStyle":
+				for _, attr := range token.Attr {
+					if attr.Name.Local == "val" && current != nil {
+						current.Style = attr.Value
+					}
+				}
+			case "t":
+				inText = true
+			}
+		case xml.EndElement:
+			switch token.Name.Local {
+			case "p":
+				out = append(out, *current)
+				current = nil
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText && current != nil {
+				current.Text += string(token)
+			}
+		}
+	}
+}
+
+func TestGenerateResumeDOCX(t *testing.T) {
+	content := resumeData
+	content.Experiences = []experience{{
+		Title:          map[lang]string{english: "Engineer <R&D>", french: "Ingénieur <R&D>"},
+		Company:        "Acme & Co",
+		Location:       "Paris",
+		From:           time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC),
+		Description:    map[lang]string{english: "Built \"things\"", french: "Construit des « choses »"},
+		SkillsAndTools: []string{"Go"},
+	}}
+	buf := &bytes.Buffer{}
+	err := generateResumeDOCX(buf, content, english)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paragraphs := readDOCXParagraphs(t, buf.Bytes())
+
+	headings := []string{}
+	subheadings := []string{}
+	for _, p := range paragraphs {
+		switch p.Style {
+		case "Heading1":
+			headings = append(headings, p.Text)
+		case "Heading2":
+			subheadings = append(subheadings, p.Text)
+		}
+	}
+	wantHeadings := []string{
+		content.ExperiencesTitle[english],
+		content.SkillsTitle[english],
+		content.LanguagesTitle[english],
+		content.ExternalLinksTitle[english],
+		content.ContactLinksTitle[english],
+	}
+	if len(headings) != len(wantHeadings) {
+		t.Fatalf("got headings %q, want %q", headings, wantHeadings)
+	}
+	for i := range wantHeadings {
+		if headings[i] != wantHeadings[i] {
+			t.Fatalf("got headings %q, want %q", headings, wantHeadings)
+		}
+	}
+	if len(subheadings) == 0 || subheadings[0] != "Engineer <R&D>" {
+		t.Fatalf("got sub-headings %q, want the experience title first", subheadings)
+	}
+
+	rows := map[string]bool{}
+	for _, p := range paragraphs {
+		if p.Style == "KeyValue" {
+			rows[p.Text] = true
+		}
+	}
+	for _, want := range []string{
+		content.ExperienceCompanyKey[english] + "Acme & Co",
+		content.ExperienceLocationKey[english] + "Paris",
+		content.ExperienceDescriptionKey[english] + "Built \"things\"",
+	} {
+		if !rows[want] {
+			t.Errorf("missing row %q", want)
+		}
+	}
+}
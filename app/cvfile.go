@@ -0,0 +1,198 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Loading and validation of résumé data files.
+
+// Layout used for experience dates in résumé data files (ex: "2023-09").
+const resumeFileDateLayout = "2006-01"
+
+// Returns the résumé stored in the given JSON file,
+// or the embedded résumé data if no file path is provided.
+func loadResume(fpath string) (resume, error) {
+	if fpath == "" {
+		return resumeData, resumeData.validate()
+	}
+	f, err := os.Open(fpath)
+	if err != nil {
+		return resume{}, err
+	}
+	defer f.Close()
+	content := resume{}
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&content)
+	if err != nil {
+		return resume{}, fmt.Errorf("decode resume file %q: %w", fpath, err)
+	}
+	err = content.validate()
+	if err != nil {
+		return resume{}, fmt.Errorf("resume file %q: %w", fpath, err)
+	}
+	return content, nil
+}
+
+// Dates are written as "YYYY-MM" in résumé data files,
+// an empty end date means that the experience is ongoing.
+type experienceJSON struct {
+	experienceAlias
+	From string `json:"from"`
+	To   string `json:"to,omitempty"`
+}
+
+type experienceAlias experience
+
+func (exp experience) MarshalJSON() ([]byte, error) {
+	out := experienceJSON{experienceAlias: experienceAlias(exp), From: exp.From.Format(resumeFileDateLayout)}
+	if !exp.To.IsZero() {
+		out.To = exp.To.Format(resumeFileDateLayout)
+	}
+	return json.Marshal(out)
+}
+
+func (exp *experience) UnmarshalJSON(raw []byte) error {
+	in := experienceJSON{}
+	dec := json.NewDecoder(bytes.NewReader(raw)) // unknown fields are rejected like in the rest of the file
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&in)
+	if err != nil {
+		return err
+	}
+	*exp = experience(in.experienceAlias)
+	exp.From, err = time.Parse(resumeFileDateLayout, in.From)
+	if err != nil {
+		return fmt.Errorf("invalid start date %q (expected format is YYYY-MM)", in.From)
+	}
+	if in.To != "" {
+		exp.To, err = time.Parse(resumeFileDateLayout, in.To)
+		if err != nil {
+			return fmt.Errorf("invalid end date %q (expected format is YYYY-MM)", in.To)
+		}
+	}
+	return nil
+}
+
+// Reports all problems found in the résumé data at once,
+// each one prefixed with the path of the invalid field (ex: "experiences[2].to").
+func (content *resume) validate() error {
+	v := &resumeValidator{}
+	v.requireText("name", content.Name)
+	v.requireTranslations("tag_line", content.TagLine)
+	v.requireText("pdf_title", content.PDFTitle)
+
+	v.requireTranslations("experiences_title", content.ExperiencesTitle)
+	v.requireTranslations("experience_duration_key", content.ExperienceDurationKey)
+	v.requireTranslations("experience_company_key", content.ExperienceCompanyKey)
+	v.requireTranslations("experience_location_key", content.ExperienceLocationKey)
+	v.requireTranslations("experience_technologies_key", content.ExperienceTechnologiesKey)
+	v.requireTranslations("experience_description_key", content.ExperienceDescriptionKey)
+	v.requireTranslations("experience_now", content.ExperienceNow)
+	for i, exp := range content.Experiences {
+		field := fmt.Sprintf("experiences[%d]", i)
+		v.requireTranslations(field+".title", exp.Title)
+		v.requireText(field+".company", exp.Company)
+		v.requireText(field+".location", exp.Location)
+		v.requireTranslations(field+".description", exp.Description)
+		switch {
+		case exp.From.IsZero():
+			v.errorf(field+".from", "missing start date")
+		case !exp.To.IsZero() && exp.To.Before(exp.From):
+			v.errorf(field+".to", "end date %s is before start date %s",
+				exp.To.Format(resumeFileDateLayout), exp.From.Format(resumeFileDateLayout))
+		}
+	}
+
+	v.requireTranslations("skills_title", content.SkillsTitle)
+	for i, skill := range content.Skills {
+		v.requireTranslations(fmt.Sprintf("skills[%d].title", i), skill.Title)
+	}
+
+	v.requireTranslations("languages_title", content.LanguagesTitle)
+	for i, language := range content.Languages {
+		field := fmt.Sprintf("languages[%d]", i)
+		v.requireTranslations(field+".name", language.Name)
+		v.requireTranslations(field+".level", language.Level)
+	}
+
+	v.requireTranslations("external_links_title", content.ExternalLinksTitle)
+	for i, link := range content.ExternalLinks {
+		field := fmt.Sprintf("external_links[%d]", i)
+		v.requireTranslations(field+".label", link.Label)
+		v.requireURL(field+".url", link.URL)
+	}
+
+	v.requireTranslations("contact_links_title", content.ContactLinksTitle)
+	for i, link := range content.ContactLinks {
+		field := fmt.Sprintf("contact_links[%d]", i)
+		v.requireTranslations(field+".label", link.Label)
+		v.requireURL(field+".url", link.URL)
+	}
+
+	v.requireTranslations("source_code_text", content.SourceCodeText)
+	v.requireURL("source_code_url", content.SourceCodeURL)
+	v.requireTranslations("generated_at", content.GeneratedAt)
+
+	content.validateVariants(v)
+	return v.err()
+}
+
+type resumeValidator struct{ problems []string }
+
+func (v *resumeValidator) errorf(field, format string, args ...any) {
+	v.problems = append(v.problems, field+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *resumeValidator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid resume data (%d problems):\n\t%s", len(v.problems), strings.Join(v.problems, "\n\t"))
+}
+
+func (v *resumeValidator) requireText(field, s string) {
+	if strings.TrimSpace(s) == "" {
+		v.errorf(field, "missing value")
+	}
+}
+
+func (v *resumeValidator) requireTranslations(field string, translations map[lang]string) {
+	for _, l := range supportedLangs {
+		if strings.TrimSpace(translations[l]) == "" {
+			v.errorf(field, "missing %q translation", l)
+		}
+	}
+	for l := range translations {
+		if !l.isSupported() {
+			v.errorf(field, "unsupported language %q", l)
+		}
+	}
+}
+
+// Only absolute "http", "https" and "mailto" URLs are allowed.
+func (v *resumeValidator) requireURL(field, rawURL string) {
+	u, err := url.Parse(rawURL)
+	switch {
+	case rawURL == "":
+		v.errorf(field, "missing URL")
+	case err != nil:
+		v.errorf(field, "malformed URL %q: %s", rawURL, err)
+	case u.Scheme == "mailto":
+		if !strings.Contains(u.Opaque, "@") {
+			v.errorf(field, "malformed email URL %q", rawURL)
+		}
+	case u.Scheme == "http" || u.Scheme == "https":
+		if u.Host == "" {
+			v.errorf(field, "missing host in URL %q", rawURL)
+		}
+	default:
+		v.errorf(field, "unsupported scheme in URL %q (expected http, https or mailto)", rawURL)
+	}
+}
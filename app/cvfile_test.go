@@ -0,0 +1,113 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Writes the embedded résumé data to a file, after applying the given changes to its JSON representation.
+func writeResumeFile(t *testing.T, edit func(doc map[string]any)) string {
+	t.Helper()
+	doc := map[string]any{}
+	mustUnmarshalJSON(mustMarshalJSON(resumeData), &doc)
+	edit(doc)
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fpath := filepath.Join(t.TempDir(), "resume.json")
+	err = os.WriteFile(fpath, raw, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fpath
+}
+
+func TestLoadResume(t *testing.T) {
+	fpath := writeResumeFile(t, func(doc map[string]any) {})
+	content, err := loadResume(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(content.Experiences) != len(resumeData.Experiences) {
+		t.Fatalf("got %d experiences, want %d", len(content.Experiences), len(resumeData.Experiences))
+	}
+}
+
+func TestLoadResumeRejectsUnknownFields(t *testing.T) {
+	tests := map[string]func(doc map[string]any){
+		"top level":  func(doc map[string]any) { doc["nmae"] = "Jane Doe" },
+		"experience": func(doc map[string]any) { doc["experiences"].([]any)[0].(map[string]any)["tag"] = []string{"devops"} },
+	}
+	for name, edit := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadResume(writeResumeFile(t, edit))
+			if err == nil || !strings.Contains(err.Error(), "unknown field") {
+				t.Fatalf("got error %v, want unknown field error", err)
+			}
+		})
+	}
+}
+
+func TestLoadResumeEmbeddedFallback(t *testing.T) {
+	content, err := loadResume("")
+	if err != nil {
+		t.Fatalf("embedded résumé data is invalid: %s", err)
+	}
+	if content.Name != resumeData.Name || len(content.Experiences) != len(resumeData.Experiences) {
+		t.Fatalf("got résumé of %q, want the embedded résumé data", content.Name)
+	}
+}
+
+func TestLoadResumeValidationErrors(t *testing.T) {
+	experience := func(doc map[string]any) map[string]any { return doc["experiences"].([]any)[0].(map[string]any) }
+	externalLink := func(doc map[string]any) map[string]any { return doc["external_links"].([]any)[0].(map[string]any) }
+	tests := map[string]struct {
+		edit func(doc map[string]any)
+		want string
+	}{
+		"missing translation": {
+			edit: func(doc map[string]any) { delete(experience(doc)["title"].(map[string]any), "fr") },
+			want: `experiences[0].title: missing "fr" translation`,
+		},
+		"unsupported language": {
+			edit: func(doc map[string]any) { doc["tag_line"].(map[string]any)["de"] = "Entwickler" },
+			want: `tag_line: unsupported language "de"`,
+		},
+		"end date before start date": {
+			edit: func(doc map[string]any) { experience(doc)["from"], experience(doc)["to"] = "2020-05", "2019-01" },
+			want: "experiences[0].to: end date 2019-01 is before start date 2020-05",
+		},
+		"malformed date": {
+			edit: func(doc map[string]any) { experience(doc)["from"] = "2020/05" },
+			want: `invalid start date "2020/05" (expected format is YYYY-MM)`,
+		},
+		"malformed URL": {
+			edit: func(doc map[string]any) { externalLink(doc)["url"] = "https://example.com/%zz" },
+			want: `external_links[0].url: malformed URL "https://example.com/%zz"`,
+		},
+		"URL without host": {
+			edit: func(doc map[string]any) { externalLink(doc)["url"] = "https:///path" },
+			want: `external_links[0].url: missing host in URL "https:///path"`,
+		},
+		"unsupported URL scheme": {
+			edit: func(doc map[string]any) { doc["source_code_url"] = "ftp://example.com" },
+			want: `source_code_url: unsupported scheme in URL "ftp://example.com"`,
+		},
+		"malformed email URL": {
+			edit: func(doc map[string]any) { doc["contact_links"].([]any)[0].(map[string]any)["url"] = "mailto:jane" },
+			want: `contact_links[0].url: malformed email URL "mailto:jane"`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadResume(writeResumeFile(t, test.edit))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Plain text and Markdown résumé generation,
+// sections are written in the same order as in the PDF.
+
+// Maximum line length of the plain text résumé.
+const resumeTextWidth = 80
+
+// Width of the key column for key/value lines of the plain text résumé.
+const resumeTextKeyWidth = 16
+
+func generateResumeText(w io.Writer, content resume, l lang) error {
+	bw := bufio.NewWriter(w)
+
+	// Add title and sub-title
+	fmt.Fprintln(bw, strings.ToUpper(content.Name))
+	fmt.Fprintln(bw, strings.Repeat("=", utf8.RuneCountInString(content.Name)))
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, wrapText(content.TagLine[l], resumeTextWidth, ""))
+
+	// Add experiences
+	writeTextHeading(bw, content.ExperiencesTitle[l])
+	for _, exp := range content.Experiences {
+		fmt.Fprintln(bw)
+		fmt.Fprintln(bw, wrapText(exp.Title[l], resumeTextWidth, ""))
+		writeTextKV(bw, content.ExperienceDurationKey[l], content.ExperiencePeriod(exp, l))
+		writeTextKV(bw, content.ExperienceCompanyKey[l], exp.Company)
+		writeTextKV(bw, content.ExperienceLocationKey[l], exp.Location)
+		writeTextKV(bw, content.ExperienceTechnologiesKey[l], strings.Join(exp.SkillsAndTools, ", "))
+		writeTextKV(bw, content.ExperienceDescriptionKey[l], exp.Description[l])
+	}
+
+	// Add skills
+	writeTextHeading(bw, content.SkillsTitle[l])
+	for _, skill := range content.Skills {
+		fmt.Fprintln(bw)
+		fmt.Fprintln(bw, wrapText(skill.Title[l], resumeTextWidth, ""))
+		fmt.Fprintln(bw, wrapText(strings.Join(skill.Tools, ", "), resumeTextWidth, "  "))
+	}
+
+	// Add languages
+	writeTextHeading(bw, content.LanguagesTitle[l])
+	fmt.Fprintln(bw)
+	for _, language := range content.Languages {
+		writeTextKV(bw, language.Name[l], language.Level[l])
+	}
+
+	// Add links
+	writeTextHeading(bw, content.ExternalLinksTitle[l])
+	fmt.Fprintln(bw)
+	for _, link := range content.ExternalLinks {
+		writeTextKV(bw, link.Label[l], link.URL)
+	}
+
+	// Add contact section
+	writeTextHeading(bw, content.ContactLinksTitle[l])
+	fmt.Fprintln(bw)
+	for _, link := range content.ContactLinks {
+		writeTextKV(bw, link.Label[l], strings.TrimPrefix(link.URL, "mailto:"))
+	}
+
+	// Add source code link
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, strings.Repeat("-", resumeTextWidth))
+	fmt.Fprintln(bw, wrapText(content.SourceCodeText[l]+content.SourceCodeURL, resumeTextWidth, ""))
+
+	return bw.Flush()
+}
+
+func writeTextHeading(w io.Writer, heading string) {
+	heading = strings.ToUpper(heading)
+	fmt.Fprintf(w, "\n\n%s\n%s\n", heading, strings.Repeat("-", utf8.RuneCountInString(heading)))
+}
+
+// Writes the key in a fixed-width column and wraps the value next to it,
+// the value starts on the next line when the key is too long.
+func writeTextKV(w io.Writer, k, v string) {
+	indent := strings.Repeat(" ", resumeTextKeyWidth+2)
+	value := wrapText(v, resumeTextWidth, indent)
+	padding := resumeTextKeyWidth - utf8.RuneCountInString(k)
+	if padding < 1 {
+		fmt.Fprintf(w, "  %s\n%s\n", k, value)
+		return
+	}
+	fmt.Fprintf(w, "  %s%s%s\n", k, strings.Repeat(" ", padding), strings.TrimPrefix(value, indent))
+}
+
+// Wraps words to the given line width, each line is prefixed with the given indentation.
+// Line breaks already present in the text are kept.
+func wrapText(s string, width int, indent string) string {
+	lines := []string{}
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := indent
+		for _, word := range strings.Fields(paragraph) {
+			switch {
+			case line == indent:
+				line += word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width:
+				lines = append(lines, line)
+				line = indent + word
+			default:
+				line += " " + word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func generateResumeMarkdown(w io.Writer, content resume, l lang) error {
+	bw := bufio.NewWriter(w)
+
+	// Add title and sub-title
+	fmt.Fprintf(bw, "# %s\n\n", escapeMarkdown(content.Name))
+	fmt.Fprintf(bw, "%s\n", strings.ReplaceAll(escapeMarkdown(content.TagLine[l]), "\n", "  \n"))
+
+	// Add experiences
+	fmt.Fprintf(bw, "\n## %s\n", escapeMarkdown(content.ExperiencesTitle[l]))
+	for _, exp := range content.Experiences {
+		fmt.Fprintf(bw, "\n### %s\n\n", escapeMarkdown(exp.Title[l]))
+		writeMarkdownKV(bw, content.ExperienceDurationKey[l], content.ExperiencePeriod(exp, l))
+		writeMarkdownKV(bw, content.ExperienceCompanyKey[l], exp.Company)
+		writeMarkdownKV(bw, content.ExperienceLocationKey[l], exp.Location)
+		writeMarkdownKV(bw, content.ExperienceTechnologiesKey[l], strings.Join(exp.SkillsAndTools, ", "))
+		writeMarkdownKV(bw, content.ExperienceDescriptionKey[l], exp.Description[l])
+	}
+
+	// Add skills
+	fmt.Fprintf(bw, "\n## %s\n\n", escapeMarkdown(content.SkillsTitle[l]))
+	for _, skill := range content.Skills {
+		writeMarkdownKV(bw, skill.Title[l], strings.Join(skill.Tools, ", "))
+	}
+
+	// Add languages
+	fmt.Fprintf(bw, "\n## %s\n\n", escapeMarkdown(content.LanguagesTitle[l]))
+	for _, language := range content.Languages {
+		writeMarkdownKV(bw, language.Name[l], language.Level[l])
+	}
+
+	// Add links
+	fmt.Fprintf(bw, "\n## %s\n\n", escapeMarkdown(content.ExternalLinksTitle[l]))
+	for _, link := range content.ExternalLinks {
+		fmt.Fprintf(bw, "- **%s**: <%s>\n", escapeMarkdown(link.Label[l]), link.URL)
+	}
+
+	// Add contact section
+	fmt.Fprintf(bw, "\n## %s\n\n", escapeMarkdown(content.ContactLinksTitle[l]))
+	for _, link := range content.ContactLinks {
+		fmt.Fprintf(bw, "- **%s**: [%s](%s)\n", escapeMarkdown(link.Label[l]), escapeMarkdown(strings.TrimPrefix(link.URL, "mailto:")), link.URL)
+	}
+
+	// Add source code link
+	fmt.Fprintf(bw, "\n---\n\n%s<%s>\n", escapeMarkdown(content.SourceCodeText[l]), content.SourceCodeURL)
+
+	return bw.Flush()
+}
+
+// Line breaks of the value are kept as hard line breaks, indented to stay in the list item.
+func writeMarkdownKV(w io.Writer, k, v string) {
+	fmt.Fprintf(w, "- **%s**: %s\n", escapeMarkdown(k), strings.ReplaceAll(escapeMarkdown(v), "\n", "  \n  "))
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+	`>`, `\>`,
+	"`", "\\`",
+)
+
+// Escapes characters that would otherwise be interpreted as inline Markdown formatting.
+func escapeMarkdown(s string) string { return markdownEscaper.Replace(s) }
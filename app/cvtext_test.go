@@ -0,0 +1,119 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// Returns a copy of the embedded résumé with Markdown special characters and long lines in its text.
+func newTestTextResume() resume {
+	content := resume{}
+	mustUnmarshalJSON(mustMarshalJSON(resumeData), &content)
+	content.RenderTime = time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	exp := &content.Experiences[0]
+	exp.Title = map[lang]string{english: "Lead *Go* developer [remote]", french: "Développeur *Go* principal [à distance]"}
+	exp.Company = "Acme_Corp <EU>"
+	exp.SkillsAndTools = []string{"C++", "`make`", "Go"}
+	exp.Description = map[lang]string{
+		english: "Built a back-office with a very long description that has to be wrapped over several lines in the plain text version of the résumé, using snake_case names and C:\\paths.\nKept line breaks.",
+		french:  "Développement d'un back-office avec une très longue description qui doit être coupée sur plusieurs lignes dans la version texte du CV, avec des noms en snake_case et des chemins C:\\.\nSauts de ligne conservés.",
+	}
+	return content
+}
+
+func TestGenerateResumeText(t *testing.T) {
+	for _, l := range supportedLangs {
+		t.Run(string(l), func(t *testing.T) {
+			content := newTestTextResume()
+			buf := &bytes.Buffer{}
+			err := generateResumeText(buf, content, l)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "resume_"+string(l)+".txt", buf.Bytes())
+
+			for i, line := range strings.Split(buf.String(), "\n") {
+				if utf8.RuneCountInString(line) > resumeTextWidth && strings.Contains(strings.TrimSpace(line), " ") {
+					t.Errorf("line %d is longer than %d characters: %q", i+1, resumeTextWidth, line)
+				}
+			}
+			checkSectionOrder(t, buf.String(), strings.ToUpper, content, l)
+		})
+	}
+}
+
+func TestGenerateResumeMarkdown(t *testing.T) {
+	for _, l := range supportedLangs {
+		t.Run(string(l), func(t *testing.T) {
+			content := newTestTextResume()
+			buf := &bytes.Buffer{}
+			err := generateResumeMarkdown(buf, content, l)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "resume_"+string(l)+".md", buf.Bytes())
+
+			for _, want := range []string{
+				`### ` + escapeMarkdown(content.Experiences[0].Title[l]),
+				`Acme\_Corp \<EU\>`,
+				"C++, \\`make\\`, Go",
+				`snake\_case`,
+				`C:\\`,
+				".  \n  " + strings.SplitN(content.Experiences[0].Description[l], "\n", 2)[1], // line breaks are kept in the list item
+			} {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output doesn't contain %q", want)
+				}
+			}
+			checkSectionOrder(t, buf.String(), func(heading string) string { return "## " + escapeMarkdown(heading) }, content, l)
+		})
+	}
+}
+
+// Checks that the sections are written in the same order as in the PDF.
+func checkSectionOrder(t *testing.T, out string, heading func(string) string, content resume, l lang) {
+	t.Helper()
+	previous := -1
+	for _, title := range []map[lang]string{
+		content.ExperiencesTitle,
+		content.SkillsTitle,
+		content.LanguagesTitle,
+		content.ExternalLinksTitle,
+		content.ContactLinksTitle,
+		content.SourceCodeText,
+	} {
+		want := heading(title[l])
+		if title[l] == content.SourceCodeText[l] {
+			want = strings.Split(title[l], " ")[0] // not a heading
+		}
+		i := strings.Index(out, want)
+		if i <= previous {
+			t.Fatalf("section %q is missing or out of order", title[l])
+		}
+		previous = i
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := map[string]struct {
+		in, indent, want string
+	}{
+		"short":              {in: "Hi world", want: "Hi world"},
+		"wrapped":            {in: "one two three four", want: "one two\nthree four"},
+		"indented":           {in: "one two three four", indent: "  ", want: "  one two\n  three\n  four"},
+		"line breaks kept":   {in: "one\ntwo", want: "one\ntwo"},
+		"long word":          {in: "a verylongwordthatdoesnotfit b", want: "a\nverylongwordthatdoesnotfit\nb"},
+		"multibyte runes":    {in: "été été été", want: "été été\nété"},
+		"whitespace removed": {in: "  one   two  ", want: "one two"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := wrapText(test.in, 10, test.indent); got != test.want {
+				t.Fatalf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
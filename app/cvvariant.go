@@ -0,0 +1,143 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// resumeVariant is a tailored version of the résumé for a type of role,
+// it only includes the experiences and skills that have at least one of its tags.
+type resumeVariant struct {
+	Name        string          `json:"name"` // used in URLs (ex: "/resume/devops")
+	Tags        []string        `json:"tags"`
+	OrderByTags bool            `json:"order_by_tags"` // entries matching the first tags come first (instead of keeping the original order)
+	TagLine     map[lang]string `json:"tag_line"`      // optional, replaces the default tag line
+}
+
+// Variant names are used as URL path segments.
+var variantNameRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Returns false if no variant has the given name.
+func (content resume) variant(name string) (resume, bool) {
+	for _, v := range content.Variants {
+		if v.Name != name {
+			continue
+		}
+		out := content
+		out.Variant = v.Name
+		if len(v.TagLine) > 0 {
+			out.TagLine = v.TagLine
+		}
+		out.Experiences = []experience{}
+		for _, exp := range content.Experiences {
+			if v.tagRank(exp.Tags) >= 0 {
+				out.Experiences = append(out.Experiences, exp)
+			}
+		}
+		out.Skills = []skill{}
+		for _, skill := range content.Skills {
+			if v.tagRank(skill.Tags) >= 0 {
+				out.Skills = append(out.Skills, skill)
+			}
+		}
+		if v.OrderByTags {
+			sort.SliceStable(out.Experiences, func(i, j int) bool {
+				return v.tagRank(out.Experiences[i].Tags) < v.tagRank(out.Experiences[j].Tags)
+			})
+			sort.SliceStable(out.Skills, func(i, j int) bool {
+				return v.tagRank(out.Skills[i].Tags) < v.tagRank(out.Skills[j].Tags)
+			})
+		}
+		return out, true
+	}
+	return resume{}, false
+}
+
+// Returns the index of the first variant tag found in the given tags, or -1 if none match.
+func (v *resumeVariant) tagRank(tags []string) int {
+	for i, variantTag := range v.Tags {
+		for _, tag := range tags {
+			if tag == variantTag {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (content *resume) validateVariants(v *resumeValidator) {
+	usedTags := map[string]bool{}
+	for _, exp := range content.Experiences {
+		for _, tag := range exp.Tags {
+			usedTags[tag] = true
+		}
+	}
+	for _, skill := range content.Skills {
+		for _, tag := range skill.Tags {
+			usedTags[tag] = true
+		}
+	}
+
+	names := map[string]bool{}
+	for i, variant := range content.Variants {
+		field := fmt.Sprintf("variants[%d]", i)
+		switch {
+		case !variantNameRegexp.MatchString(variant.Name):
+			v.errorf(field+".name", "%q is not a valid name (only lowercase letters, digits and dashes are allowed)", variant.Name)
+		case lang(variant.Name).isSupported():
+			v.errorf(field+".name", "%q is reserved for the translated résumé", variant.Name)
+		case names[variant.Name]:
+			v.errorf(field+".name", "duplicate variant %q", variant.Name)
+		}
+		names[variant.Name] = true
+		if len(variant.Tags) == 0 {
+			v.errorf(field+".tags", "missing tags")
+		}
+		for _, tag := range variant.Tags {
+			if !usedTags[tag] {
+				v.errorf(field+".tags", "tag %q is not used by any experience or skill", tag)
+			}
+		}
+		if len(variant.TagLine) > 0 {
+			v.requireTranslations(field+".tag_line", variant.TagLine)
+		}
+	}
+}
+
+// Returns the URL path of the résumé page (ex: "/fr/resume/devops").
+func resumePagePath(variant string, l lang) string {
+	path := "/resume"
+	if variant != "" {
+		path += "/" + variant
+	}
+	return localizedPath(l, path)
+}
+
+// Returns the URL path of a downloadable résumé file (ex: "/resume_fr.pdf" or "/resume/devops_fr.pdf").
+func resumeFilePath(variant string, l lang, ext string) string {
+	path := "/resume"
+	if variant != "" {
+		path += "/" + variant
+	}
+	if l != english {
+		path += "_" + string(l)
+	}
+	return path + ext
+}
+
+// Returns the URL path of the résumé PDF, used for the download link of résumé pages.
+func (content resume) PDFPath(l lang) string { return resumeFilePath(content.Variant, l, ".pdf") }
+
+// Returns the name under which a résumé file is saved when downloaded (ex: "resume_jane_doe_devops_fr.pdf").
+func (content resume) downloadFilename(l lang, ext string) string {
+	name := "resume_" + strings.ToLower(strings.Join(strings.Fields(content.Name), "_"))
+	if content.Variant != "" {
+		name += "_" + content.Variant
+	}
+	if l != english {
+		name += "_" + string(l)
+	}
+	return name + ext
+}
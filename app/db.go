@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"go.etcd.io/bbolt"
@@ -16,7 +17,15 @@ type DB interface {
 	CountHTTPRequests(from, to time.Time) (int, error)
 	GetAverageTimeToHandleHTTPRequest(from, to time.Time) (time.Duration, error)
 	GetNumRequestPerURL(from, to time.Time) (map[string]int, error)
+	GetNumRequestsPerHour(from, to time.Time) ([]int, error)
 	CountVisitors(from, to time.Time) (int, error)
+	StoreContactMessage(*contactMessage) error
+	CountContactMessagesFromVisitor(visitorHash string, from, to time.Time) (int, error)
+	CountContactMessagesFromEmail(email string, from, to time.Time) (int, error)
+	StoreRejectedSubmission(*rejectedSubmission) error
+	CountRejectedSubmissions(from, to time.Time) (map[string]int, error)
+	GetFileVersions() (map[string]fileVersion, error)
+	StoreFileVersions(map[string]fileVersion) error
 }
 
 type boltDB struct {
@@ -25,7 +34,10 @@ type boltDB struct {
 }
 
 var (
-	boltHTTPRequestsBucket = []byte("http_requests")
+	boltHTTPRequestsBucket        = []byte("http_requests")
+	boltContactMessagesBucket     = []byte("contact_messages")
+	boltRejectedSubmissionsBucket = []byte("rejected_submissions")
+	boltFileVersionsBucket        = []byte("file_versions") // ETag and modification time of prerendered files, by URL path
 )
 
 func newBoltDB() *boltDB {
@@ -43,6 +55,13 @@ func newBoltDB() *boltDB {
 	err = db.Update(func(tx *bbolt.Tx) error {
 		for _, bucketID := range [][]byte{
 			boltHTTPRequestsBucket,
+			boltContactMessagesBucket,
+			boltRejectedSubmissionsBucket,
+			boltFileVersionsBucket,
+			boltEmailOutboxBucket,
+			boltEmailDeadLettersBucket,
+			boltEmailSentKeysBucket,
+			boltEmailPendingKeysBucket,
 		} {
 			_, err := tx.CreateBucketIfNotExists(bucketID)
 			if err != nil {
@@ -101,6 +120,23 @@ func (db *boltDB) GetNumRequestPerURL(from, to time.Time) (map[string]int, error
 	})
 }
 
+// Returns the number of requests of each hour of the period, the last hour may be incomplete.
+func (db *boltDB) GetNumRequestsPerHour(from, to time.Time) ([]int, error) {
+	numHours := int((to.Sub(from) + time.Hour - 1) / time.Hour)
+	if numHours < 0 {
+		numHours = 0
+	}
+	out := make([]int, numHours)
+	return out, db.readTimeRange(boltHTTPRequestsBucket, from, to, func(k, v []byte) error {
+		req := &httpRequest{}
+		mustUnmarshalJSON(v, req)
+		if i := int(req.CreatedAt.Sub(from) / time.Hour); i >= 0 && i < numHours {
+			out[i]++
+		}
+		return nil
+	})
+}
+
 func (db *boltDB) CountVisitors(from, to time.Time) (int, error) {
 	visitorHashes := map[string]struct{}{}
 	err := db.readTimeRange(boltHTTPRequestsBucket, from, to, func(k, v []byte) error {
@@ -112,6 +148,89 @@ func (db *boltDB) CountVisitors(from, to time.Time) (int, error) {
 	return len(visitorHashes), err
 }
 
+func (db *boltDB) StoreContactMessage(msg *contactMessage) error {
+	return db.f.Update(func(tx *bbolt.Tx) error {
+		key := []byte(msg.CreatedAt.Format(time.RFC3339) + msg.ID)
+		return tx.Bucket(boltContactMessagesBucket).Put(key, mustMarshalJSON(msg))
+	})
+}
+
+func (db *boltDB) CountContactMessagesFromVisitor(visitorHash string, from, to time.Time) (int, error) {
+	count := 0
+	return count, db.readTimeRange(boltContactMessagesBucket, from, to, func(k, v []byte) error {
+		msg := &contactMessage{}
+		mustUnmarshalJSON(v, msg)
+		if msg.VisitorHash == visitorHash {
+			count++
+		}
+		return nil
+	})
+}
+
+// Email addresses are compared case-insensitively.
+func (db *boltDB) CountContactMessagesFromEmail(email string, from, to time.Time) (int, error) {
+	count := 0
+	return count, db.readTimeRange(boltContactMessagesBucket, from, to, func(k, v []byte) error {
+		msg := &contactMessage{}
+		mustUnmarshalJSON(v, msg)
+		if strings.EqualFold(msg.Email, email) {
+			count++
+		}
+		return nil
+	})
+}
+
+func (db *boltDB) StoreRejectedSubmission(sub *rejectedSubmission) error {
+	return db.f.Update(func(tx *bbolt.Tx) error {
+		key := []byte(sub.CreatedAt.Format(time.RFC3339) + sub.ID)
+		return tx.Bucket(boltRejectedSubmissionsBucket).Put(key, mustMarshalJSON(sub))
+	})
+}
+
+// Returns the number of rejected submissions by reason.
+func (db *boltDB) CountRejectedSubmissions(from, to time.Time) (map[string]int, error) {
+	out := map[string]int{}
+	return out, db.readTimeRange(boltRejectedSubmissionsBucket, from, to, func(k, v []byte) error {
+		sub := &rejectedSubmission{}
+		mustUnmarshalJSON(v, sub)
+		out[sub.Reason]++
+		return nil
+	})
+}
+
+func (db *boltDB) GetFileVersions() (map[string]fileVersion, error) {
+	out := map[string]fileVersion{}
+	return out, db.f.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltFileVersionsBucket).ForEach(func(k, v []byte) error {
+			version := fileVersion{}
+			mustUnmarshalJSON(v, &version)
+			out[string(k)] = version
+			return nil
+		})
+	})
+}
+
+// Replaces the stored versions (files that are no longer rendered are removed).
+func (db *boltDB) StoreFileVersions(versions map[string]fileVersion) error {
+	return db.f.Update(func(tx *bbolt.Tx) error {
+		err := tx.DeleteBucket(boltFileVersionsBucket)
+		if err != nil {
+			return err
+		}
+		bucket, err := tx.CreateBucket(boltFileVersionsBucket)
+		if err != nil {
+			return err
+		}
+		for path, version := range versions {
+			err := bucket.Put([]byte(path), mustMarshalJSON(version))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (db *boltDB) readTimeRange(bucket []byte, from, to time.Time, cb func(k, v []byte) error) error {
 	return db.f.View(func(tx *bbolt.Tx) error {
 		min := []byte(from.Format(time.RFC3339))
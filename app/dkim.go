@@ -0,0 +1,167 @@
+package app
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DKIM signing of outgoing emails (RFC 6376), with RSA-SHA256 or Ed25519-SHA256 (RFC 8463) keys
+// and the relaxed canonicalization of headers and body.
+//
+// The public key must be published in a TXT record of the "<selector>._domainkey.<domain>" DNS name
+// (ex: "v=DKIM1; k=rsa; p=<base64 encoded public key>").
+
+type dkimSigner struct {
+	domain    string
+	selector  string
+	key       crypto.Signer
+	algorithm string // "rsa-sha256" or "ed25519-sha256"
+}
+
+// Headers covered by the signature (when present in the message).
+var dkimSignedHeaders = []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type"}
+
+// Returns nil if DKIM signing is not configured.
+func loadDKIMSigner(config *Config) (*dkimSigner, error) {
+	if config.DKIMDomain == "" && config.DKIMSelector == "" && config.DKIMPrivateKeyPath == "" {
+		return nil, nil
+	}
+	if config.DKIMDomain == "" || config.DKIMSelector == "" || config.DKIMPrivateKeyPath == "" {
+		return nil, fmt.Errorf("DKIM domain, selector and private key path must be set together")
+	}
+	raw, err := os.ReadFile(config.DKIMPrivateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parseDKIMPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("DKIM private key %q: %w", config.DKIMPrivateKeyPath, err)
+	}
+	signer := &dkimSigner{domain: config.DKIMDomain, selector: config.DKIMSelector, key: key}
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		signer.algorithm = "rsa-sha256"
+	case ed25519.PrivateKey:
+		signer.algorithm = "ed25519-sha256"
+	}
+	return signer, nil
+}
+
+// Supports PEM encoded PKCS #8 keys (RSA or Ed25519) and PKCS #1 keys (RSA).
+func parseDKIMPrivateKey(raw []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (expected RSA or Ed25519)", key)
+	}
+}
+
+// Returns the message with a DKIM-Signature header prepended.
+// The message must use CRLF line endings, as produced by emailMessageStr.
+func (signer *dkimSigner) sign(msg string, t time.Time) (string, error) {
+	header, body, _ := strings.Cut(msg, "\r\n\r\n")
+	bodyHash := sha256.Sum256([]byte(dkimRelaxedBody(body)))
+
+	// Headers to sign, in the order of the "h" tag
+	fields := dkimHeaderFields(header)
+	signedNames := []string{}
+	signedData := ""
+	for _, name := range dkimSignedHeaders {
+		if field, ok := fields[strings.ToLower(name)]; ok {
+			signedNames = append(signedNames, strings.ToLower(name))
+			signedData += dkimRelaxedHeader(field) + "\r\n"
+		}
+	}
+
+	// The signature covers the DKIM-Signature header itself, with an empty "b" tag
+	sigHeader := "DKIM-Signature: v=1; a=" + signer.algorithm + "; c=relaxed/relaxed;\r\n" +
+		"\td=" + signer.domain + "; s=" + signer.selector + "; t=" + strconv.FormatInt(t.Unix(), 10) + ";\r\n" +
+		"\th=" + strings.Join(signedNames, ":") + ";\r\n" +
+		"\tbh=" + base64.StdEncoding.EncodeToString(bodyHash[:]) + ";\r\n" +
+		"\tb="
+	signedData += dkimRelaxedHeader(sigHeader)
+	digest := sha256.Sum256([]byte(signedData))
+
+	var signature []byte
+	var err error
+	switch key := signer.key.(type) {
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, digest[:]) // the hash is signed, see RFC 8463
+	default:
+		signature, err = signer.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return "", fmt.Errorf("DKIM sign: %w", err)
+	}
+	return sigHeader + base64.StdEncoding.EncodeToString(signature) + "\r\n" + msg, nil
+}
+
+// Returns the header fields by lowercase name (with continuation lines),
+// only the last occurrence of each field is kept.
+func dkimHeaderFields(header string) map[string]string {
+	out := map[string]string{}
+	lines := strings.Split(header, "\r\n")
+	for i := 0; i < len(lines); i++ {
+		field := lines[i]
+		for i+1 < len(lines) && (strings.HasPrefix(lines[i+1], " ") || strings.HasPrefix(lines[i+1], "\t")) {
+			i++
+			field += "\r\n" + lines[i]
+		}
+		name, _, ok := strings.Cut(field, ":")
+		if ok {
+			out[strings.ToLower(strings.TrimSpace(name))] = field
+		}
+	}
+	return out
+}
+
+var dkimWhitespaceRegexp = regexp.MustCompile(`[ \t]+`)
+
+// Lowercases the name, unfolds the value, reduces whitespace to single spaces and removes it around the colon.
+func dkimRelaxedHeader(field string) string {
+	name, value, _ := strings.Cut(field, ":")
+	value = strings.ReplaceAll(value, "\r\n", "")
+	value = strings.TrimSpace(dkimWhitespaceRegexp.ReplaceAllString(value, " "))
+	return strings.ToLower(strings.TrimSpace(name)) + ":" + value
+}
+
+// Reduces whitespace to single spaces, removes it at the end of lines, and removes empty lines at the end of the body.
+func dkimRelaxedBody(body string) string {
+	lines := strings.Split(body, "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(dkimWhitespaceRegexp.ReplaceAllString(line, " "), " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDKIMRelaxedCanonicalization(t *testing.T) {
+	// Example from RFC 6376, section 3.4.5
+	fields := dkimHeaderFields("A: X\r\nB : Y\t\r\n\tZ  ")
+	if got := dkimRelaxedHeader(fields["a"]) + "\r\n" + dkimRelaxedHeader(fields["b"]) + "\r\n"; got != "a:X\r\nb:Y Z\r\n" {
+		t.Errorf("got canonicalized headers %q", got)
+	}
+	if got := dkimRelaxedBody(" C \r\nD \t E\r\n\r\n\r\n"); got != " C\r\nD E\r\n" {
+		t.Errorf("got canonicalized body %q", got)
+	}
+	if got := dkimRelaxedBody("\r\n\r\n"); got != "" {
+		t.Errorf("got canonicalized empty body %q", got)
+	}
+}
+
+// Writes the key to a PEM file and returns a signer loaded from it.
+func newTestDKIMSigner(t *testing.T, blockType string, der []byte) *dkimSigner {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dkim.pem")
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := loadDKIMSigner(&Config{DKIMDomain: "example.com", DKIMSelector: "mail", DKIMPrivateKeyPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+// Verifies the DKIM-Signature header of a signed message with the given public key.
+func verifyTestDKIMSignature(t *testing.T, signed string, pub crypto.PublicKey) error {
+	t.Helper()
+	header, body, _ := strings.Cut(signed, "\r\n\r\n")
+	fields := dkimHeaderFields(header)
+	sigField, ok := fields["dkim-signature"]
+	if !ok {
+		t.Fatal("missing DKIM-Signature header")
+	}
+	_, value, _ := strings.Cut(sigField, ":")
+	tags := map[string]string{}
+	for _, tag := range strings.Split(value, ";") {
+		name, value, _ := strings.Cut(tag, "=")
+		tags[strings.TrimSpace(name)] = strings.Join(strings.Fields(value), "")
+	}
+	if tags["v"] != "1" || tags["c"] != "relaxed/relaxed" || tags["d"] != "example.com" || tags["s"] != "mail" {
+		t.Fatalf("got tags %v", tags)
+	}
+
+	bodyHash := sha256.Sum256([]byte(dkimRelaxedBody(body)))
+	if got := base64.StdEncoding.EncodeToString(bodyHash[:]); got != tags["bh"] {
+		t.Fatalf("got body hash %q, want %q", tags["bh"], got)
+	}
+
+	signedData := ""
+	for _, name := range strings.Split(tags["h"], ":") {
+		signedData += dkimRelaxedHeader(fields[name]) + "\r\n"
+	}
+	b := regexp.MustCompile(`[;\s]b=`).FindStringIndex(sigField) // the signature itself is left out
+	signedData += dkimRelaxedHeader(sigField[:b[1]])
+	digest := sha256.Sum256([]byte(signedData))
+
+	sig, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if tags["a"] != "rsa-sha256" {
+			t.Fatalf("got algorithm %q", tags["a"])
+		}
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig)
+	case ed25519.PublicKey:
+		if tags["a"] != "ed25519-sha256" {
+			t.Fatalf("got algorithm %q", tags["a"])
+		}
+		if !ed25519.Verify(pub, digest[:], sig) {
+			return errors.New("ed25519: verification error")
+		}
+		return nil
+	}
+	t.Fatalf("unsupported public key %T", pub)
+	return nil
+}
+
+func TestDKIMSign(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPKCS8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPKCS8, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		signer *dkimSigner
+		pub    crypto.PublicKey
+	}{
+		"RSA PKCS #1": {newTestDKIMSigner(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), &rsaKey.PublicKey},
+		"RSA PKCS #8": {newTestDKIMSigner(t, "PRIVATE KEY", rsaPKCS8), &rsaKey.PublicKey},
+		"Ed25519":     {newTestDKIMSigner(t, "PRIVATE KEY", edPKCS8), edPub},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			msg := emailMessageStr(&Email{
+				From:          "Website <website@example.com>",
+				To:            []string{"jane@example.com"},
+				Subject:       "Un sujet assez long pour que l'en-tête encodé soit replié sur plusieurs lignes",
+				PlainTextBody: "Hello  Jane, \n\n\n",
+				HTMLBody:      "<p>Hello Jane</p>",
+			})
+			signed, err := test.signer.sign(msg, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(signed, msg) {
+				t.Fatal("the signed message must end with the original message")
+			}
+			parseTestEmail(t, signed)
+			err = verifyTestDKIMSignature(t, signed, test.pub)
+			if err != nil {
+				t.Fatalf("invalid signature: %s", err)
+			}
+
+			// Changing a signed header invalidates the signature
+			tampered := strings.Replace(signed, "\r\nTo: jane@example.com\r\n", "\r\nTo: john@example.com\r\n", 1)
+			if tampered == signed {
+				t.Fatal("To header not found")
+			}
+			if verifyTestDKIMSignature(t, tampered, test.pub) == nil {
+				t.Fatal("signature still valid after changing the To header")
+			}
+		})
+	}
+}
@@ -1,11 +1,17 @@
 package app
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
 	"io"
-	"net/smtp"
-	"strconv"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
 	"strings"
+	"time"
 )
 
 type Email struct {
@@ -13,6 +19,16 @@ type Email struct {
 	To            []string
 	Subject       string
 	PlainTextBody string
+	HTMLBody      string // optional, sent as an alternative to the plain text body
+	Attachments   []emailAttachment
+	// Optional, emails with the same key are only sent once (emails without key are always sent)
+	IdempotencyKey string
+}
+
+type emailAttachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
 }
 
 type Emailer func(*Email) error
@@ -28,12 +44,18 @@ func sendEmailToAdmin(config *Config, emailer Emailer, subject, msg string) erro
 
 func newMockEmailer(w io.Writer, err error) Emailer {
 	return func(email *Email) error {
-		msg := fmt.Sprintf("New email: \n\tFrom: %s\n\tTo: %s\n\tSubject: %s\n\tBody:\n\n%s\n",
+		msg := fmt.Sprintf("New email: \n\tFrom: %s\n\tTo: %s\n\tSubject: %s\n",
 			email.From,
 			email.To,
 			email.Subject,
-			email.PlainTextBody,
 		)
+		if email.HTMLBody != "" {
+			msg += "\tHTML body: yes\n"
+		}
+		for _, attachment := range email.Attachments {
+			msg += fmt.Sprintf("\tAttachment: %s (%s, %d bytes)\n", attachment.Filename, attachment.ContentType, len(attachment.Content))
+		}
+		msg += fmt.Sprintf("\tBody:\n\n%s\n", email.PlainTextBody)
 		if err != nil {
 			return err
 		}
@@ -42,27 +64,131 @@ func newMockEmailer(w io.Writer, err error) Emailer {
 	}
 }
 
-func newSMTPEmailer(config *Config) Emailer {
-	auth := smtp.PlainAuth("", config.SMTPUsername, config.SMTPPassword, config.SMTPHost)
-	return func(email *Email) error {
-		addr := config.SMTPHost + ":" + strconv.Itoa(config.SMTPPort)
-		return smtp.SendMail(addr, auth, config.SMTPSender, email.To, []byte(emailMessageStr(email)))
+// generates the message string that will be sent to the SMTP server:
+// the quoted-printable plain text body, in a multipart/alternative part if there is an HTML body,
+// itself in a multipart/mixed part if there are attachments.
+func emailMessageStr(e *Email) string {
+	buf := &bytes.Buffer{}
+	writeEmailHeader(buf, "From", encodeAddress(e.From))
+	writeEmailHeader(buf, "To", strings.Join(e.To, ", "))
+	writeEmailHeader(buf, "Subject", mime.QEncoding.Encode("utf-8", e.Subject))
+	writeEmailHeader(buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeEmailHeader(buf, "Message-ID", newMessageID(e.From))
+	writeEmailHeader(buf, "MIME-Version", "1.0")
+
+	header, body := emailBody(e)
+	if len(e.Attachments) == 0 {
+		for _, key := range []string{"Content-Type", "Content-Transfer-Encoding"} {
+			if value := header.Get(key); value != "" {
+				writeEmailHeader(buf, key, value)
+			}
+		}
+		buf.WriteString("\r\n")
+		buf.Write(body)
+		return buf.String()
 	}
+
+	// Attachments come after the message body
+	mixed := multipart.NewWriter(buf)
+	writeEmailHeader(buf, "Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mixed.Boundary()}))
+	buf.WriteString("\r\n")
+	mustCreateEmailPart(mixed, header).Write(body)
+	for _, attachment := range e.Attachments {
+		w := mustCreateEmailPart(mixed, textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(attachment.ContentType, map[string]string{"name": attachment.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		writeBase64Lines(w, attachment.Content)
+	}
+	mixed.Close()
+	return buf.String()
 }
 
-// generates the message string that will be sent to the SMTP server
-func emailMessageStr(e *Email) string {
-	headerMap := map[string]string{
-		"From":         e.From,
-		"To":           strings.Join(e.To, "; "),
-		"Subject":      e.Subject,
-		"MIME-Version": "1.0",
-		"Content-Type": "text/plain",
+// Returns the header and the content of the message body (without attachments).
+func emailBody(e *Email) (textproto.MIMEHeader, []byte) {
+	body := &bytes.Buffer{}
+	if e.HTMLBody == "" {
+		writeQuotedPrintable(body, e.PlainTextBody)
+		return textproto.MIMEHeader{
+			"Content-Type":              {"text/plain; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		}, body.Bytes()
+	}
+	alternative := multipart.NewWriter(body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", e.PlainTextBody},
+		{"text/html; charset=utf-8", e.HTMLBody}, // last since the preferred alternative comes last
+	} {
+		w := mustCreateEmailPart(alternative, textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		writeQuotedPrintable(w, part.content)
 	}
-	header := ""
-	for key, val := range headerMap {
-		header += key + ":" + val + "\r\n"
+	alternative.Close()
+	return textproto.MIMEHeader{
+		"Content-Type": {mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": alternative.Boundary()})},
+	}, body.Bytes()
+}
+
+func writeEmailHeader(w io.Writer, key, value string) {
+	fmt.Fprintf(w, "%s: %s\r\n", key, value)
+}
+
+// Writing to a bytes.Buffer never fails.
+func mustCreateEmailPart(w *multipart.Writer, header textproto.MIMEHeader) io.Writer {
+	part, err := w.CreatePart(header)
+	if err != nil {
+		panic(err)
+	}
+	return part
+}
+
+// Line breaks are converted to CRLF as required by the SMTP protocol.
+func writeQuotedPrintable(w io.Writer, s string) {
+	qp := quotedprintable.NewWriter(w)
+	qp.Write([]byte(strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")))
+	qp.Close()
+	io.WriteString(w, "\r\n")
+}
+
+// Lines of base64 encoded content must not be longer than 76 characters.
+func writeBase64Lines(w io.Writer, content []byte) {
+	const lineLength = 76
+	encoded := base64.StdEncoding.EncodeToString(content)
+	for len(encoded) > lineLength {
+		io.WriteString(w, encoded[:lineLength]+"\r\n")
+		encoded = encoded[lineLength:]
+	}
+	io.WriteString(w, encoded+"\r\n")
+}
+
+// Encodes the display name of the address if needed (ex: "Jérôme <jerome@example.com>").
+func encodeAddress(s string) string {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return s
+	}
+	return addr.String()
+}
+
+// Returns the email address without display name, as expected in SMTP commands (ex: "jane@example.com" for "Jane <jane@example.com>").
+func envelopeAddress(s string) string {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return s
+	}
+	return addr.Address
+}
+
+// Returns a unique message ID using the domain of the sender address (ex: "<3f2a...@example.com>").
+func newMessageID(from string) string {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if _, host, ok := strings.Cut(addr.Address, "@"); ok {
+			domain = host
+		}
 	}
-	body := e.PlainTextBody
-	return header + "\r\n" + body + "\r\n"
+	return "<" + newID(16) + "@" + domain + ">"
 }
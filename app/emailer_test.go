@@ -0,0 +1,210 @@
+package app
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// Parses a raw message, failing the test if lines don't end with CRLF or are longer than 998 characters.
+func parseTestEmail(t *testing.T, raw string) *mail.Message {
+	t.Helper()
+	if !strings.HasSuffix(raw, "\r\n") {
+		t.Fatalf("message doesn't end with CRLF:\n%s", raw)
+	}
+	for i, line := range strings.Split(strings.TrimSuffix(raw, "\r\n"), "\r\n") {
+		if strings.Contains(line, "\n") || len(line) > 998 {
+			t.Fatalf("line %d is not properly terminated or too long: %q", i+1, line)
+		}
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+type testEmailPart struct {
+	Header textproto.MIMEHeader
+	Body   io.Reader // not decoded
+}
+
+// Returns the parts of a multipart entity with the given media type.
+func readTestParts(t *testing.T, contentType string, body io.Reader, wantMediaType string) []testEmailPart {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType != wantMediaType {
+		t.Fatalf("got media type %q, want %q", mediaType, wantMediaType)
+	}
+	parts := []testEmailPart{}
+	mr := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := mr.NextRawPart() // a part can only be read until the next one
+		if err == io.EOF {
+			return parts
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, testEmailPart{Header: part.Header, Body: bytes.NewReader(raw)})
+	}
+}
+
+// Checks the content of a quoted-printable text part, whose lines must not be longer than 76 characters.
+func checkQuotedPrintable(t *testing.T, header textproto.MIMEHeader, body io.Reader, wantContentType, want string) {
+	t.Helper()
+	if got := header.Get("Content-Type"); got != wantContentType {
+		t.Errorf("got content type %q, want %q", got, wantContentType)
+	}
+	if got := header.Get("Content-Transfer-Encoding"); got != "quoted-printable" {
+		t.Errorf("got transfer encoding %q, want quoted-printable", got)
+	}
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("quoted-printable line longer than 76 characters: %q", line)
+		}
+	}
+	decoded, err := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSuffix(string(decoded), "\r\n"); got != want {
+		t.Errorf("got decoded body %q, want %q", got, want)
+	}
+}
+
+func TestEmailMessageStrHeaders(t *testing.T) {
+	raw := emailMessageStr(&Email{
+		From:          "Jérôme <jerome@example.com>",
+		To:            []string{"jane@example.com", "john@example.org"},
+		Subject:       "Café ☕ and more",
+		PlainTextBody: "Hello",
+	})
+	msg := parseTestEmail(t, raw)
+
+	// Headers are written in a fixed order
+	names := []string{}
+	header, _, _ := strings.Cut(raw, "\r\n\r\n")
+	for _, line := range strings.Split(header, "\r\n") {
+		name, _, _ := strings.Cut(line, ":")
+		names = append(names, name)
+	}
+	want := "From To Subject Date Message-ID MIME-Version Content-Type Content-Transfer-Encoding"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("got headers %q, want %q", got, want)
+	}
+
+	if got := msg.Header.Get("From"); got != "=?utf-8?q?J=C3=A9r=C3=B4me?= <jerome@example.com>" {
+		t.Errorf("got From %q", got)
+	}
+	if got := msg.Header.Get("To"); got != "jane@example.com, john@example.org" {
+		t.Errorf("got To %q", got)
+	}
+	subject := msg.Header.Get("Subject")
+	if !strings.HasPrefix(subject, "=?utf-8?q?") {
+		t.Errorf("subject %q is not RFC 2047 encoded", subject)
+	}
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(subject); err != nil || decoded != "Café ☕ and more" {
+		t.Errorf("got decoded subject %q (%v)", decoded, err)
+	}
+	if _, err := msg.Header.Date(); err != nil {
+		t.Errorf("invalid Date: %s", err)
+	}
+	if got := msg.Header.Get("Message-ID"); !strings.HasPrefix(got, "<") || !strings.HasSuffix(got, "@example.com>") {
+		t.Errorf("got Message-ID %q", got)
+	}
+	if got := msg.Header.Get("MIME-Version"); got != "1.0" {
+		t.Errorf("got MIME-Version %q", got)
+	}
+}
+
+func TestEmailMessageStrPlainText(t *testing.T) {
+	text := "Première ligne, avec un signe = et une ligne assez longue pour être coupée en plusieurs lignes par l'encodage.\nSecond line"
+	msg := parseTestEmail(t, emailMessageStr(&Email{From: "website@example.com", To: []string{"jane@example.com"}, PlainTextBody: text}))
+	checkQuotedPrintable(t, textproto.MIMEHeader(msg.Header), msg.Body, "text/plain; charset=utf-8", strings.ReplaceAll(text, "\n", "\r\n"))
+}
+
+func TestEmailMessageStrAlternative(t *testing.T) {
+	msg := parseTestEmail(t, emailMessageStr(&Email{
+		From:          "website@example.com",
+		To:            []string{"jane@example.com"},
+		PlainTextBody: "Hello Jane",
+		HTMLBody:      `<p style="color: red">Hello Jane</p>`,
+	}))
+	if got := msg.Header.Get("Content-Transfer-Encoding"); got != "" {
+		t.Errorf("got transfer encoding %q for a multipart message", got)
+	}
+	parts := readTestParts(t, msg.Header.Get("Content-Type"), msg.Body, "multipart/alternative")
+	if len(parts) != 2 {
+		t.Fatalf("got %d parts, want 2", len(parts))
+	}
+	checkQuotedPrintable(t, parts[0].Header, parts[0].Body, "text/plain; charset=utf-8", "Hello Jane")
+	checkQuotedPrintable(t, parts[1].Header, parts[1].Body, "text/html; charset=utf-8", `<p style="color: red">Hello Jane</p>`)
+}
+
+func TestEmailMessageStrAttachment(t *testing.T) {
+	content := bytes.Repeat([]byte("%PDF-1.4 \x00\xff"), 50)
+	msg := parseTestEmail(t, emailMessageStr(&Email{
+		From:          "website@example.com",
+		To:            []string{"jane@example.com"},
+		PlainTextBody: "Hello Jane",
+		HTMLBody:      "<p>Hello Jane</p>",
+		Attachments:   []emailAttachment{{Filename: "résumé.pdf", ContentType: "application/pdf", Content: content}},
+	}))
+	parts := readTestParts(t, msg.Header.Get("Content-Type"), msg.Body, "multipart/mixed")
+	if len(parts) != 2 {
+		t.Fatalf("got %d parts, want 2", len(parts))
+	}
+
+	// The message body comes first
+	alternatives := readTestParts(t, parts[0].Header.Get("Content-Type"), parts[0].Body, "multipart/alternative")
+	if len(alternatives) != 2 {
+		t.Fatalf("got %d alternatives, want 2", len(alternatives))
+	}
+
+	attachment := parts[1]
+	if got := attachment.Header.Get("Content-Transfer-Encoding"); got != "base64" {
+		t.Errorf("got transfer encoding %q, want base64", got)
+	}
+	if mediaType, params, err := mime.ParseMediaType(attachment.Header.Get("Content-Type")); err != nil || mediaType != "application/pdf" || params["name"] != "résumé.pdf" {
+		t.Errorf("got content type %q", attachment.Header.Get("Content-Type"))
+	}
+	if disposition, params, err := mime.ParseMediaType(attachment.Header.Get("Content-Disposition")); err != nil || disposition != "attachment" || params["filename"] != "résumé.pdf" {
+		t.Errorf("got content disposition %q", attachment.Header.Get("Content-Disposition"))
+	}
+	raw, err := io.ReadAll(attachment.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(raw), "\r\n"), "\r\n")
+	for _, line := range lines {
+		if len(line) > 76 {
+			t.Errorf("base64 line longer than 76 characters: %q", line)
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(lines, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, content) {
+		t.Errorf("attachment content changed")
+	}
+}
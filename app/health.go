@@ -5,9 +5,12 @@ import (
 	"encoding/base32"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,31 +22,91 @@ type report struct {
 	NumVisitors         int
 	NumRequests         int
 	NumRequestPerURL    map[string]int
+	NumRequestsPerHour  []int // from the start of the period
 	AverageTimeToHandle time.Duration
+
+	// Spam protection
+	NumRejectedSubmissions map[string]int // by reason
+
+	Previous *report // report of the previous period of the same duration (nil for the previous report itself)
 }
 
 func (r *report) String() string {
 	out := fmt.Sprintf("# Health report (%s to %s)\n\n", r.From.Format(time.RFC3339), r.To.Format(time.RFC3339))
 	out += "## Traffic\n\n"
-	out += fmt.Sprintf("%-25s %s\n", "Number of visitors:", strconv.Itoa(r.NumVisitors))
-	out += fmt.Sprintf("%-25s %s\n", "Number of requests:", strconv.Itoa(r.NumRequests))
-	out += fmt.Sprintf("%-25s %s\n", "Avg. time to handle:", r.AverageTimeToHandle)
+	out += fmt.Sprintf("%-25s %s%s\n", "Number of visitors:", strconv.Itoa(r.NumVisitors), r.textDelta(func(r *report) float64 { return float64(r.NumVisitors) }))
+	out += fmt.Sprintf("%-25s %s%s\n", "Number of requests:", strconv.Itoa(r.NumRequests), r.textDelta(func(r *report) float64 { return float64(r.NumRequests) }))
+	out += fmt.Sprintf("%-25s %s%s\n", "Avg. time to handle:", r.AverageTimeToHandle, r.textDelta(func(r *report) float64 { return float64(r.AverageTimeToHandle) }))
 	out += "Most requested URLs:\n"
-	type urlStat struct {
-		url      string
-		requests int
+	for _, stat := range r.TopURLs(0) {
+		out += fmt.Sprintf("\t* %-10s %q\n", strconv.Itoa(stat.Requests), stat.URL)
+	}
+	out += "\n## Forms\n\n"
+	out += fmt.Sprintf("%-25s %s\n", "Rejected submissions:", strconv.Itoa(r.NumRejected()))
+	for _, stat := range r.RejectionReasons() {
+		out += fmt.Sprintf("\t* %-10s %s\n", strconv.Itoa(stat.Count), stat.Reason)
+	}
+	return out
+}
+
+// Returns the change compared to the previous period (ex: " (+12% vs. previous period)"), or an empty string if unknown.
+func (r *report) textDelta(value func(*report) float64) string {
+	if r.Previous == nil {
+		return ""
 	}
-	urlStats := make([]urlStat, 0, len(r.NumRequestPerURL))
+	delta := percentDelta(value(r), value(r.Previous))
+	if delta == "" {
+		return ""
+	}
+	return " (" + delta + " vs. previous period)"
+}
+
+type urlStat struct {
+	URL      string
+	Requests int
+}
+
+// Returns the most requested URLs (all of them if n is 0).
+func (r *report) TopURLs(n int) []urlStat {
+	out := make([]urlStat, 0, len(r.NumRequestPerURL))
 	for url, requests := range r.NumRequestPerURL {
-		urlStats = append(urlStats, urlStat{url, requests})
+		out = append(out, urlStat{url, requests})
 	}
-	sort.Slice(urlStats, func(i, j int) bool { return urlStats[i].requests > urlStats[j].requests })
-	for _, stat := range urlStats {
-		out += fmt.Sprintf("\t* %-10s %q\n", strconv.Itoa(stat.requests), stat.url)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Requests != out[j].Requests {
+			return out[i].Requests > out[j].Requests
+		}
+		return out[i].URL < out[j].URL
+	})
+	if n > 0 && len(out) > n {
+		out = out[:n]
 	}
 	return out
 }
 
+func (r *report) NumRejected() int {
+	total := 0
+	for _, count := range r.NumRejectedSubmissions {
+		total += count
+	}
+	return total
+}
+
+type rejectionStat struct {
+	Reason string
+	Count  int
+}
+
+// Returns the number of rejected submissions by reason, sorted by reason.
+func (r *report) RejectionReasons() []rejectionStat {
+	out := make([]rejectionStat, 0, len(r.NumRejectedSubmissions))
+	for reason, count := range r.NumRejectedSubmissions {
+		out = append(out, rejectionStat{reason, count})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Reason < out[j].Reason })
+	return out
+}
+
 type httpRequest struct {
 	ID            string
 	CreatedAt     time.Time
@@ -54,7 +117,17 @@ type httpRequest struct {
 	TimeToHandle  time.Duration
 }
 
+// Returns the report of the given period, compared to the previous period of the same duration.
 func generateReport(db DB, from, to time.Time) (*report, error) {
+	out, err := generatePeriodReport(db, from, to)
+	if err != nil {
+		return nil, err
+	}
+	out.Previous, err = generatePeriodReport(db, from.Add(-to.Sub(from)), from)
+	return out, err
+}
+
+func generatePeriodReport(db DB, from, to time.Time) (*report, error) {
 	numVisitors, err := db.CountVisitors(from, to)
 	if err != nil {
 		return nil, err
@@ -71,39 +144,44 @@ func generateReport(db DB, from, to time.Time) (*report, error) {
 	if err != nil {
 		return nil, err
 	}
+	numRequestsPerHour, err := db.GetNumRequestsPerHour(from, to)
+	if err != nil {
+		return nil, err
+	}
+	numRejectedSubmissions, err := db.CountRejectedSubmissions(from, to)
+	if err != nil {
+		return nil, err
+	}
 	return &report{
-		From:                from,
-		To:                  to,
-		NumVisitors:         numVisitors,
-		NumRequests:         numHTTPRequests,
-		AverageTimeToHandle: averageTimeToHandle,
-		NumRequestPerURL:    numRequestPerURL,
+		From:                   from,
+		To:                     to,
+		NumVisitors:            numVisitors,
+		NumRequests:            numHTTPRequests,
+		AverageTimeToHandle:    averageTimeToHandle,
+		NumRequestPerURL:       numRequestPerURL,
+		NumRequestsPerHour:     numRequestsPerHour,
+		NumRejectedSubmissions: numRejectedSubmissions,
 	}, nil
 }
 
-func newRequestTrackingMiddleware(db DB) func(http.Handler) http.Handler {
+func newRequestTrackingMiddleware(config *Config, db DB) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			before := time.Now()
 			next.ServeHTTP(w, r) // serve request
 			after := time.Now()
 
-			visitorHash, err := newVisitorHash(r)
-			if err != nil {
-				panic(err)
-			}
-
 			// Store request in DB
 			req := &httpRequest{
 				ID:            newID(32),
 				CreatedAt:     time.Now(),
-				VisitorHash:   visitorHash,
+				VisitorHash:   newVisitorHash(r, config.TrustedProxies),
 				URL:           r.URL.String(),
 				ContentLength: r.ContentLength,
 				TimeToHandle:  after.Sub(before),
 				UserAgent:     r.UserAgent(),
 			}
-			err = db.StoreHTTPRequest(req)
+			err := db.StoreHTTPRequest(req)
 			if err != nil {
 				log.Println(err)
 				return
@@ -112,28 +190,59 @@ func newRequestTrackingMiddleware(db DB) func(http.Handler) http.Handler {
 	}
 }
 
-func getIPAddr(r *http.Request) (string, error) {
-	// Check for reverse proxy header
-	remoteAddr := r.Header.Get("X-Forwarded-For")
-	if remoteAddr == "" {
-		remoteAddr = r.RemoteAddr
+// Returns the IP address of the client.
+// The X-Forwarded-For header is only used for requests coming from a trusted reverse proxy (since clients can set it),
+// its last entry is then the address of the client as seen by the proxy.
+func getIPAddr(r *http.Request, trustedProxies []string) string {
+	// Remove the port, which changes with every connection (the address is kept as is if it has none)
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host, trustedProxies) {
+		if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() && r.Header.Get("X-Forwarded-For") != "" {
+			untrustedProxyWarning.Do(func() {
+				log.Printf("ignoring X-Forwarded-For header sent by %s, add it to \"trusted_proxies\" in the config if it is a reverse proxy", host)
+			})
+		}
+		return host
+	}
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	if last := strings.TrimSpace(hops[len(hops)-1]); last != "" {
+		return last
+	}
+	return host
+}
+
+// Logs a warning the first time a local reverse proxy is ignored (all visitors would then share its address).
+var untrustedProxyWarning sync.Once
+
+// Reports whether the IP address matches one of the trusted proxies (IP addresses or CIDR ranges).
+func isTrustedProxy(host string, trustedProxies []string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, proxy := range trustedProxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil && network.Contains(ip) {
+			return true
+		}
+		if proxyIP := net.ParseIP(proxy); proxyIP != nil && proxyIP.Equal(ip) {
+			return true
+		}
 	}
-	return remoteAddr, nil
+	return false
 }
 
-func newVisitorHash(r *http.Request) (string, error) {
+func newVisitorHash(r *http.Request, trustedProxies []string) string {
 	// Hash IP addr and user-agent
 	hash := sha1.New()
-	ip, err := getIPAddr(r)
-	if err != nil {
-		return "", err
-	}
-	_, err = hash.Write([]byte(ip + r.UserAgent()))
+	_, err := hash.Write([]byte(getIPAddr(r, trustedProxies) + r.UserAgent()))
 	if err != nil {
 		panic(err)
 	}
 	// Return base32 hex encoded hash
-	return base32.HexEncoding.EncodeToString(hash.Sum(nil)), nil
+	return base32.HexEncoding.EncodeToString(hash.Sum(nil))
 }
 
 func doPeriodicHealthReport(config *Config, emailer Emailer, db DB) {
@@ -142,7 +251,7 @@ func doPeriodicHealthReport(config *Config, emailer Emailer, db DB) {
 	if err != nil {
 		panic(err)
 	}
-	err = sendEmailToAdmin(config, emailer, "New startup report for juliensellier.com", report.String())
+	err = sendReportToAdmin(config, emailer, "New startup report for juliensellier.com", report, "")
 	if err != nil {
 		log.Println(err)
 	}
@@ -170,7 +279,9 @@ func doPeriodicHealthReport(config *Config, emailer Emailer, db DB) {
 			log.Println(err)
 			continue
 		}
-		err = sendEmailToAdmin(config, emailer, subjectPrefix+" on juliensellier.com", report.String())
+		// Keyed by period, since the same minute could be checked twice
+		reportKey := "health_report:" + from.Format("2006-01-02") + "/" + t.Format("2006-01-02")
+		err = sendReportToAdmin(config, emailer, subjectPrefix+" on juliensellier.com", report, reportKey)
 		if err != nil {
 			log.Println(err)
 		}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"bytes"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGetIPAddr(t *testing.T) {
+	trustedProxies := []string{"127.0.0.1", "::1", "10.0.0.0/8"}
+	tests := map[string]struct {
+		remoteAddr   string
+		forwardedFor string
+		want         string
+	}{
+		"direct":                          {remoteAddr: "192.0.2.1:1234", want: "192.0.2.1"},
+		"address without port":            {remoteAddr: "unix-socket", want: "unix-socket"},
+		"spoofed header":                  {remoteAddr: "192.0.2.1:1234", forwardedFor: "203.0.113.9", want: "192.0.2.1"},
+		"trusted proxy":                   {remoteAddr: "127.0.0.1:80", forwardedFor: "203.0.113.9", want: "203.0.113.9"},
+		"trusted proxy with spoofed hops": {remoteAddr: "127.0.0.1:80", forwardedFor: "198.51.100.7, 203.0.113.9", want: "203.0.113.9"},
+		"trusted proxy range":             {remoteAddr: "10.1.2.3:80", forwardedFor: "203.0.113.9", want: "203.0.113.9"},
+		"trusted IPv6 proxy":              {remoteAddr: "[::1]:80", forwardedFor: "2001:db8::1", want: "2001:db8::1"},
+		"trusted proxy without header":    {remoteAddr: "127.0.0.1:80", want: "127.0.0.1"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = test.remoteAddr
+			if test.forwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", test.forwardedFor)
+			}
+			if got := getIPAddr(r, trustedProxies); got != test.want {
+				t.Fatalf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// Clients must not be able to get a new visitor hash (and bypass rate limits) by setting the X-Forwarded-For header.
+func TestNewVisitorHashIgnoresSpoofedHeader(t *testing.T) {
+	hashes := map[string]bool{}
+	for _, forwardedFor := range []string{"", "203.0.113.1", "203.0.113.2"} {
+		r := httptest.NewRequest("POST", "/contact", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		r.Header.Set("X-Forwarded-For", forwardedFor)
+		hashes[newVisitorHash(r, nil)] = true
+	}
+	if len(hashes) != 1 {
+		t.Fatalf("got %d different visitor hashes, want 1", len(hashes))
+	}
+}
+
+func TestGetIPAddrWarnsAboutUntrustedLocalProxy(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+	untrustedProxyWarning = sync.Once{}
+
+	for _, remoteAddr := range []string{"192.0.2.1:1234", "127.0.0.1:80", "[::1]:80"} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("X-Forwarded-For", "203.0.113.9")
+		getIPAddr(r, nil)
+	}
+	if got := strings.Count(buf.String(), "trusted_proxies"); got != 1 {
+		t.Fatalf("got %d warnings, want 1 for the first request of a local proxy:\n%s", got, buf)
+	}
+	if !strings.Contains(buf.String(), "127.0.0.1") {
+		t.Errorf("the warning doesn't mention the address of the proxy:\n%s", buf)
+	}
+}
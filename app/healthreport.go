@@ -0,0 +1,85 @@
+package app
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTML version of health reports, sent along with the plain text version.
+
+const reportTopURLs = 10 // number of URLs in the table of the most requested URLs
+
+var healthReportTmpl = template.Must(template.New("_health_report.gohtml").Funcs(template.FuncMap{
+	"sparkline": sparkline,
+	"delta":     percentDelta,
+	"float":     func(n int) float64 { return float64(n) },
+	"duration":  func(d time.Duration) float64 { return float64(d) },
+}).ParseFS(uiFS, "ui/_health_report.gohtml"))
+
+func (r *report) HTML() (string, error) {
+	buf := &strings.Builder{}
+	err := healthReportTmpl.ExecuteTemplate(buf, "health_report", map[string]any{
+		"Report":  r,
+		"TopURLs": r.TopURLs(reportTopURLs),
+	})
+	return buf.String(), err
+}
+
+// Sends the report as an HTML email with the plain text version as an alternative,
+// or only the plain text version if the HTML version can't be rendered.
+// Reports with an idempotency key (ex: one per period) are only sent once.
+func sendReportToAdmin(config *Config, emailer Emailer, subject string, r *report, idempotencyKey string) error {
+	html, err := r.HTML()
+	if err != nil {
+		log.Println(err)
+		html = ""
+	}
+	return emailer(&Email{
+		From:           config.SMTPSender,
+		To:             []string{config.AdminEmailAddr},
+		Subject:        subject,
+		PlainTextBody:  r.String(),
+		HTMLBody:       html,
+		IdempotencyKey: idempotencyKey,
+	})
+}
+
+// Returns the change from the previous value (ex: "+12%"), or an empty string if there was no previous value.
+func percentDelta(value, previous float64) string {
+	if previous == 0 {
+		return ""
+	}
+	delta := (value - previous) / previous * 100
+	if delta >= 0 {
+		return fmt.Sprintf("+%.0f%%", delta)
+	}
+	return fmt.Sprintf("%.0f%%", delta)
+}
+
+// Returns an inline SVG line chart of the given values (scaled to the highest one).
+func sparkline(values []int) template.HTML {
+	const width, height, padding = 300.0, 40.0, 2.0
+	highest := 1
+	for _, v := range values {
+		if v > highest {
+			highest = v
+		}
+	}
+	points := make([]string, 0, len(values))
+	for i, v := range values {
+		x := 0.0
+		if len(values) > 1 {
+			x = float64(i) / float64(len(values)-1) * width
+		}
+		y := height - padding - float64(v)/float64(highest)*(height-2*padding)
+		points = append(points, strconv.FormatFloat(x, 'f', 1, 64)+","+strconv.FormatFloat(y, 'f', 1, 64))
+	}
+	return template.HTML(fmt.Sprintf( // only contains numbers
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f">`+
+			`<polyline fill="none" stroke="#2563eb" stroke-width="1.5" points="%s"/></svg>`,
+		width, height, width, height, strings.Join(points, " ")))
+}
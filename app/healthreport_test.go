@@ -0,0 +1,48 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportHTMLTrafficTable(t *testing.T) {
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	newReport := func(visitors int) *report {
+		return &report{From: to.Add(-24 * time.Hour), To: to, NumVisitors: visitors, NumRequests: visitors * 3}
+	}
+	withPrevious := newReport(12)
+	withPrevious.Previous = newReport(10)
+
+	tests := map[string]struct {
+		report    *report
+		wantCells int
+	}{
+		"without previous report": {newReport(12), 2},
+		"with previous report":    {withPrevious, 4},
+	}
+	cellRegexp := regexp.MustCompile(`<t[hd][ >]`)
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			html, err := test.report.HTML()
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, traffic, _ := strings.Cut(html, "<h2>Traffic</h2>")
+			traffic, _, _ = strings.Cut(traffic, "</table>")
+			rows := strings.Split(traffic, "<tr>")[1:]
+			if len(rows) != 5 {
+				t.Fatalf("got %d rows, want 5", len(rows))
+			}
+			for i, row := range rows {
+				if got := len(cellRegexp.FindAllString(row, -1)); got != test.wantCells {
+					t.Errorf("row %d has %d cells, want %d:\n%s", i, got, test.wantCells, row)
+				}
+			}
+		})
+	}
+	if html, _ := withPrevious.HTML(); !strings.Contains(html, "20%") {
+		t.Errorf("change from the previous period not found")
+	}
+}
@@ -1,8 +1,176 @@
 package app
 
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
 type lang string
 
 const (
 	english lang = "en"
 	french  lang = "fr"
 )
+
+// Languages that all translated content must be available in.
+var supportedLangs = []lang{english, french}
+
+func (l lang) isSupported() bool {
+	for _, supported := range supportedLangs {
+		if l == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the URL path of a page in the given language,
+// pages in other languages than English are prefixed with the language code (ex: "/fr/contact").
+func localizedPath(l lang, path string) string {
+	switch {
+	case l == english:
+		return path
+	case path == "/":
+		return "/" + string(l)
+	default:
+		return "/" + string(l) + path
+	}
+}
+
+// Returns the language prefix of the URL path (English if there is none) and the path without it.
+func splitLangPath(path string) (lang, string) {
+	for _, l := range supportedLangs {
+		switch prefix := "/" + string(l); {
+		case l == english:
+			continue
+		case path == prefix:
+			return l, "/"
+		case strings.HasPrefix(path, prefix+"/"):
+			return l, strings.TrimPrefix(path, prefix)
+		}
+	}
+	return english, path
+}
+
+// Returns the supported language best matching the Accept-Language header (ex: "fr-CA,fr;q=0.9,en;q=0.8"),
+// regional variants match their base language and English is used by default.
+func negotiateLang(acceptLanguage string) lang {
+	best, bestQuality := english, 0.0
+	for _, v := range parseQualityValues(acceptLanguage) {
+		base, _, _ := strings.Cut(v.value, "-")
+		l := lang(base)
+		if base == "*" {
+			l = english
+		}
+		if l.isSupported() && v.quality > bestQuality {
+			best, bestQuality = l, v.quality
+		}
+	}
+	return best
+}
+
+// Name of the cookie storing the language chosen with the language switcher.
+const langCookieName = "lang"
+
+// Redirects visitors to the translated version of the pages they request (ex: "/contact" to "/fr/contact"),
+// the language is the one chosen with the language switcher (stored in a cookie) or negotiated with the Accept-Language header.
+//
+// Language switcher links have a "lang" query parameter (ex: "/contact?lang=en"):
+// the choice is stored and the visitor is redirected to the page without it.
+// Only used for page routes (files are available in every language at any URL).
+func newLangNegotiationMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pathLang, path := splitLangPath(r.URL.Path)
+
+			// Store the language chosen by the visitor
+			if chosen := lang(r.URL.Query().Get("lang")); chosen.isSupported() {
+				http.SetCookie(w, &http.Cookie{
+					Name:     langCookieName,
+					Value:    string(chosen),
+					Path:     "/",
+					MaxAge:   365 * 24 * 60 * 60,
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+				http.Redirect(w, r, localizedPath(chosen, path), http.StatusSeeOther)
+				return
+			}
+			if pathLang != english {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Redirect to the preferred language
+			w.Header().Add("Vary", "Accept-Language, Cookie")
+			preferred := negotiateLang(r.Header.Get("Accept-Language"))
+			if cookie, err := r.Cookie(langCookieName); err == nil && lang(cookie.Value).isSupported() {
+				preferred = lang(cookie.Value)
+			}
+			if preferred != english {
+				http.Redirect(w, r, localizedPath(preferred, path), http.StatusFound)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Translated UI messages, one JSON file per language in the "messages" directory (ex: "messages/fr.json").
+//
+//go:embed messages
+var messagesFS embed.FS
+
+var messages = mustLoadMessages()
+
+// Panics if a supported language has no message file or if a message is missing in any language.
+func mustLoadMessages() map[lang]map[string]string {
+	out := map[lang]map[string]string{}
+	keys := map[string]bool{}
+	for _, l := range supportedLangs {
+		raw, err := messagesFS.ReadFile("messages/" + string(l) + ".json")
+		if err != nil {
+			panic(err)
+		}
+		langMessages := map[string]string{}
+		err = json.Unmarshal(raw, &langMessages)
+		if err != nil {
+			panic(fmt.Errorf("decode %q messages: %w", l, err))
+		}
+		out[l] = langMessages
+		for key := range langMessages {
+			keys[key] = true
+		}
+	}
+
+	problems := []string{}
+	for _, l := range supportedLangs {
+		for key := range keys {
+			if strings.TrimSpace(out[l][key]) == "" {
+				problems = append(problems, fmt.Sprintf("%s: missing %q translation", key, l))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		panic(fmt.Errorf("invalid messages (%d problems):\n\t%s", len(problems), strings.Join(problems, "\n\t")))
+	}
+	return out
+}
+
+// Returns the message in the given language, formatted with the given arguments (if any).
+// Used in templates: {{ t .Lang "nav.home" }}
+func translate(l lang, key string, args ...any) (string, error) {
+	msg, ok := messages[l][key]
+	if !ok {
+		return "", fmt.Errorf("unknown message %q in %q", key, l)
+	}
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	return msg, nil
+}
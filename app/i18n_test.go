@@ -0,0 +1,71 @@
+package app
+
+import (
+	"encoding/json"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var formatVerbRegexp = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z%]`)
+
+// Every message file must have the same keys as the English one, with the same format verbs.
+func TestMessageCatalogues(t *testing.T) {
+	fpaths, err := fs.Glob(messagesFS, "messages/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalogues := map[lang]map[string]string{}
+	for _, fpath := range fpaths {
+		raw, err := messagesFS.ReadFile(fpath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l := lang(strings.TrimSuffix(path.Base(fpath), ".json"))
+		if !l.isSupported() {
+			t.Errorf("%s: unsupported language %q", fpath, l)
+		}
+		catalogue := map[string]string{}
+		err = json.Unmarshal(raw, &catalogue)
+		if err != nil {
+			t.Fatalf("%s: %s", fpath, err)
+		}
+		catalogues[l] = catalogue
+	}
+	for _, l := range supportedLangs {
+		if _, ok := catalogues[l]; !ok {
+			t.Fatalf("missing message file for %q", l)
+		}
+	}
+
+	ref := catalogues[english]
+	for l, catalogue := range catalogues {
+		for key, msg := range ref {
+			translated, ok := catalogue[key]
+			if !ok {
+				t.Errorf("%s: missing %q", l, key)
+				continue
+			}
+			got, want := formatVerbRegexp.FindAllString(translated, -1), formatVerbRegexp.FindAllString(msg, -1)
+			if strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("%s: %q has format verbs %q instead of %q", l, key, got, want)
+			}
+		}
+		for key := range catalogue {
+			if _, ok := ref[key]; !ok {
+				t.Errorf("%s: %q is not in the %q messages", l, key, english)
+			}
+		}
+	}
+}
+
+func TestMustLoadMessages(t *testing.T) {
+	loaded := mustLoadMessages()
+	for _, l := range supportedLangs {
+		if len(loaded[l]) != len(loaded[english]) {
+			t.Errorf("%s: got %d messages, want %d", l, len(loaded[l]), len(loaded[english]))
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Conversion from and to the JSON Resume standard (https://jsonresume.org/schema).
+//
+// A JSON Resume document only holds one language,
+// section titles and other UI strings are not part of the standard
+// and come from the embedded résumé data when importing.
+// Tags, language flags and variants are kept in "x-" prefixed extension fields so that they survive a round trip.
+
+const jsonResumeSchemaURL = "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json"
+
+type jsonResume struct {
+	Schema    string               `json:"$schema,omitempty"`
+	Basics    jsonResumeBasics     `json:"basics"`
+	Work      []jsonResumeWork     `json:"work"`
+	Skills    []jsonResumeSkill    `json:"skills"`
+	Languages []jsonResumeLanguage `json:"languages"`
+	Meta      *jsonResumeMeta      `json:"meta,omitempty"`
+	Variants  []resumeVariant      `json:"x-variants,omitempty"`
+}
+
+type jsonResumeBasics struct {
+	Name     string              `json:"name"`
+	Label    string              `json:"label,omitempty"`
+	Email    string              `json:"email,omitempty"`
+	URL      string              `json:"url,omitempty"`
+	Summary  string              `json:"summary,omitempty"`
+	Profiles []jsonResumeProfile `json:"profiles"`
+}
+
+type jsonResumeProfile struct {
+	Network  string `json:"network"`
+	Username string `json:"username,omitempty"`
+	URL      string `json:"url"`
+}
+
+type jsonResumeWork struct {
+	Name      string `json:"name"`
+	Position  string `json:"position"`
+	Location  string `json:"location,omitempty"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate,omitempty"`
+	Summary   string `json:"summary,omitempty"`
+	// Not part of the standard (which allows additional properties),
+	// used to preserve the skills and tools of each experience.
+	Keywords []string `json:"keywords,omitempty"`
+	Tags     []string `json:"x-tags,omitempty"`
+}
+
+type jsonResumeSkill struct {
+	Name     string   `json:"name"`
+	Keywords []string `json:"keywords"`
+	Tags     []string `json:"x-tags,omitempty"`
+}
+
+type jsonResumeLanguage struct {
+	Language string `json:"language"`
+	Fluency  string `json:"fluency"`
+	Flag     string `json:"x-flag,omitempty"`
+}
+
+type jsonResumeMeta struct {
+	Canonical string `json:"canonical,omitempty"`
+	Version   string `json:"version,omitempty"`
+}
+
+func generateJSONResume(w io.Writer, content resume, l lang) error {
+	doc := &jsonResume{
+		Schema: jsonResumeSchemaURL,
+		Basics: jsonResumeBasics{
+			Name:     content.Name,
+			Summary:  content.TagLine[l],
+			Profiles: []jsonResumeProfile{},
+		},
+		Work:      make([]jsonResumeWork, 0, len(content.Experiences)),
+		Skills:    make([]jsonResumeSkill, 0, len(content.Skills)),
+		Languages: make([]jsonResumeLanguage, 0, len(content.Languages)),
+		Meta:      &jsonResumeMeta{Canonical: defaultBranding.URL + resumePagePath(content.Variant, l), Version: "v1.0.0"},
+		Variants:  content.Variants,
+	}
+	if len(content.Experiences) > 0 {
+		doc.Basics.Label = content.Experiences[0].Title[l]
+	}
+	for _, link := range content.ContactLinks {
+		if strings.HasPrefix(link.URL, "mailto:") && doc.Basics.Email == "" {
+			doc.Basics.Email = strings.TrimPrefix(link.URL, "mailto:")
+		}
+	}
+	for _, link := range content.ExternalLinks {
+		doc.Basics.Profiles = append(doc.Basics.Profiles, jsonResumeProfile{
+			Network:  link.Label[l],
+			Username: profileUsername(link.URL),
+			URL:      link.URL,
+		})
+	}
+	for _, exp := range content.Experiences {
+		work := jsonResumeWork{
+			Name:      exp.Company,
+			Position:  exp.Title[l],
+			Location:  exp.Location,
+			StartDate: exp.From.Format(resumeFileDateLayout),
+			Summary:   exp.Description[l],
+			Keywords:  exp.SkillsAndTools,
+			Tags:      exp.Tags,
+		}
+		if !exp.To.IsZero() {
+			work.EndDate = exp.To.Format(resumeFileDateLayout)
+		}
+		doc.Work = append(doc.Work, work)
+	}
+	for _, skill := range content.Skills {
+		doc.Skills = append(doc.Skills, jsonResumeSkill{Name: skill.Title[l], Keywords: skill.Tools, Tags: skill.Tags})
+	}
+	for _, language := range content.Languages {
+		doc.Languages = append(doc.Languages, jsonResumeLanguage{Language: language.Name[l], Fluency: language.Level[l], Flag: language.Flag})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(doc)
+}
+
+// Returns the last segment of a profile URL path (ex: "ejuju" for "https://github.com/ejuju").
+func profileUsername(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	return segments[len(segments)-1]
+}
+
+// Builds a résumé from one JSON Resume document per supported language.
+// Entries are matched by position across languages,
+// so all documents must list the same experiences, skills, languages and profiles in the same order.
+// A single document (in any language) may be given instead, its text is then used for all languages.
+func importJSONResume(docs map[lang]*jsonResume) (resume, error) {
+	refLang := english
+	if len(docs) == 1 {
+		for l := range docs {
+			refLang = l
+		}
+	}
+	ref, ok := docs[refLang]
+	if !ok {
+		return resume{}, fmt.Errorf("missing %q document", english)
+	}
+	for l, doc := range docs {
+		switch {
+		case !l.isSupported():
+			return resume{}, fmt.Errorf("unsupported language %q", l)
+		case len(doc.Work) != len(ref.Work):
+			return resume{}, fmt.Errorf("%q document has %d work entries instead of %d", l, len(doc.Work), len(ref.Work))
+		case len(doc.Skills) != len(ref.Skills):
+			return resume{}, fmt.Errorf("%q document has %d skills instead of %d", l, len(doc.Skills), len(ref.Skills))
+		case len(doc.Languages) != len(ref.Languages):
+			return resume{}, fmt.Errorf("%q document has %d languages instead of %d", l, len(doc.Languages), len(ref.Languages))
+		case len(doc.Basics.Profiles) != len(ref.Basics.Profiles):
+			return resume{}, fmt.Errorf("%q document has %d profiles instead of %d", l, len(doc.Basics.Profiles), len(ref.Basics.Profiles))
+		}
+	}
+
+	// Section titles and other UI strings are copied from the embedded résumé
+	content := resume{
+		Name:                      ref.Basics.Name,
+		TagLine:                   map[lang]string{},
+		PDFTitle:                  resumeData.PDFTitle,
+		ExperiencesTitle:          copyTranslations(resumeData.ExperiencesTitle),
+		Experiences:               make([]experience, len(ref.Work)),
+		ExperienceDurationKey:     copyTranslations(resumeData.ExperienceDurationKey),
+		ExperienceCompanyKey:      copyTranslations(resumeData.ExperienceCompanyKey),
+		ExperienceLocationKey:     copyTranslations(resumeData.ExperienceLocationKey),
+		ExperienceTechnologiesKey: copyTranslations(resumeData.ExperienceTechnologiesKey),
+		ExperienceDescriptionKey:  copyTranslations(resumeData.ExperienceDescriptionKey),
+		ExperienceNow:             copyTranslations(resumeData.ExperienceNow),
+		SkillsTitle:               copyTranslations(resumeData.SkillsTitle),
+		Skills:                    make([]skill, len(ref.Skills)),
+		LanguagesTitle:            copyTranslations(resumeData.LanguagesTitle),
+		Languages:                 make([]language, len(ref.Languages)),
+		ExternalLinksTitle:        copyTranslations(resumeData.ExternalLinksTitle),
+		ExternalLinks:             make([]externalLink, len(ref.Basics.Profiles)),
+		ContactLinksTitle:         copyTranslations(resumeData.ContactLinksTitle),
+		SourceCodeText:            copyTranslations(resumeData.SourceCodeText),
+		SourceCodeURL:             resumeData.SourceCodeURL,
+		GeneratedAt:               copyTranslations(resumeData.GeneratedAt),
+	}
+	if ref.Basics.Email != "" && len(resumeData.ContactLinks) > 0 {
+		content.ContactLinks = []contactLink{{Label: copyTranslations(resumeData.ContactLinks[0].Label), URL: "mailto:" + ref.Basics.Email}}
+	}
+	for _, variant := range ref.Variants {
+		tagLine := copyTranslations(variant.TagLine)
+		for _, l := range supportedLangs {
+			if _, ok := tagLine[l]; !ok && len(tagLine) > 0 {
+				tagLine[l] = variant.TagLine[refLang]
+			}
+		}
+		content.Variants = append(content.Variants, resumeVariant{
+			Name:        variant.Name,
+			Tags:        variant.Tags,
+			OrderByTags: variant.OrderByTags,
+			TagLine:     tagLine,
+		})
+	}
+
+	for i, work := range ref.Work {
+		from, err := parseJSONResumeDate(work.StartDate)
+		if err != nil {
+			return resume{}, fmt.Errorf("work[%d].startDate: %w", i, err)
+		}
+		to := time.Time{}
+		if work.EndDate != "" {
+			to, err = parseJSONResumeDate(work.EndDate)
+			if err != nil {
+				return resume{}, fmt.Errorf("work[%d].endDate: %w", i, err)
+			}
+		}
+		content.Experiences[i] = experience{
+			Title:          map[lang]string{},
+			Description:    map[lang]string{},
+			Company:        work.Name,
+			Location:       work.Location,
+			From:           from,
+			To:             to,
+			SkillsAndTools: work.Keywords,
+			Tags:           work.Tags,
+		}
+	}
+	for i, s := range ref.Skills {
+		content.Skills[i] = skill{Title: map[lang]string{}, Tools: s.Keywords, Tags: s.Tags}
+	}
+	for i, l := range ref.Languages {
+		content.Languages[i] = language{Flag: l.Flag, Name: map[lang]string{}, Level: map[lang]string{}}
+	}
+	for i, profile := range ref.Basics.Profiles {
+		content.ExternalLinks[i] = externalLink{Label: map[lang]string{}, URL: profile.URL}
+	}
+
+	// Fill translations, languages without a document get the text of the reference one
+	for _, l := range supportedLangs {
+		doc, ok := docs[l]
+		if !ok {
+			doc = ref
+		}
+		content.TagLine[l] = doc.Basics.Summary
+		for i, work := range doc.Work {
+			content.Experiences[i].Title[l] = work.Position
+			content.Experiences[i].Description[l] = work.Summary
+		}
+		for i, s := range doc.Skills {
+			content.Skills[i].Title[l] = s.Name
+		}
+		for i, language := range doc.Languages {
+			content.Languages[i].Name[l] = language.Language
+			content.Languages[i].Level[l] = language.Fluency
+		}
+		for i, profile := range doc.Basics.Profiles {
+			content.ExternalLinks[i].Label[l] = profile.Network
+		}
+	}
+
+	return content, content.validate()
+}
+
+func copyTranslations(translations map[lang]string) map[lang]string {
+	if translations == nil {
+		return nil
+	}
+	out := make(map[lang]string, len(translations))
+	for l, s := range translations {
+		out[l] = s
+	}
+	return out
+}
+
+// JSON Resume dates may be written as "YYYY", "YYYY-MM" or "YYYY-MM-DD".
+func parseJSONResumeDate(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", resumeFileDateLayout, "2006"} {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// ImportJSONResume converts JSON Resume documents (one per language)
+// into a résumé data file that can be used as "resume_data_path" in the config.
+//
+// Each argument is formatted as "lang:path" (ex: "en:resume.json"),
+// a single document may be given, its text is then used for all languages.
+func ImportJSONResume(w io.Writer, args []string) error {
+	docs := map[lang]*jsonResume{}
+	for _, arg := range args {
+		l, fpath, ok := strings.Cut(arg, ":")
+		if !ok {
+			return fmt.Errorf("invalid argument %q (expected format is lang:path)", arg)
+		}
+		raw, err := os.ReadFile(fpath)
+		if err != nil {
+			return err
+		}
+		doc := &jsonResume{}
+		err = json.Unmarshal(raw, doc)
+		if err != nil {
+			return fmt.Errorf("decode %q: %w", fpath, err)
+		}
+		docs[lang(l)] = doc
+	}
+	content, err := importJSONResume(docs)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(content)
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// Compares the output to the golden file, or overwrites the golden file when running "go test -update".
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	fpath := filepath.Join("testdata", name)
+	if *updateGolden {
+		err := os.WriteFile(fpath, got, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("output differs from %s (run \"go test -update\" if the change is expected)", fpath)
+	}
+}
+
+func TestGenerateJSONResume(t *testing.T) {
+	for _, l := range supportedLangs {
+		t.Run(string(l), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := generateJSONResume(buf, resumeData, l)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "jsonresume_"+string(l)+".json", buf.Bytes())
+		})
+	}
+}
+
+func TestImportJSONResume(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := ImportJSONResume(buf, []string{"fr:" + filepath.Join("testdata", "jsonresume_import_fr.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "jsonresume_import_fr.golden.json", buf.Bytes())
+}
+
+func TestImportJSONResumeRequiresEnglishWithSeveralDocuments(t *testing.T) {
+	doc := &jsonResume{Basics: jsonResumeBasics{Name: "Jane Doe"}}
+	_, err := importJSONResume(map[lang]*jsonResume{french: doc, "de": doc})
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("got error %v, want missing document error", err)
+	}
+}
+
+// Exporting the résumé in every language and importing the documents must give back the same résumé.
+func TestJSONResumeRoundTrip(t *testing.T) {
+	docs := map[lang]*jsonResume{}
+	for _, l := range supportedLangs {
+		buf := &bytes.Buffer{}
+		err := generateJSONResume(buf, resumeData, l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs[l] = &jsonResume{}
+		mustUnmarshalJSON(buf.Bytes(), docs[l])
+	}
+	content, err := importJSONResume(docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, want := mustMarshalJSON(content), mustMarshalJSON(resumeData)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("round trip changed the résumé:\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// The embedded résumé must not be modified by the import
+	content.Experiences[0].Title[english] = "changed"
+	content.ExperiencesTitle[english] = "changed"
+	if !bytes.Equal(mustMarshalJSON(resumeData), want) {
+		t.Fatal("importing shares data with the embedded résumé")
+	}
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale-aware formatting of numbers, dates and quantities.
+
+// Plural categories, as defined by the CLDR plural rules (only the ones used by supported languages).
+type pluralCategory int
+
+const (
+	pluralOne pluralCategory = iota
+	pluralMany
+	pluralOther
+)
+
+// Returns the plural category of an integer quantity in the given language.
+func (l lang) pluralCategory(n int) pluralCategory {
+	if n < 0 {
+		n = -n
+	}
+	switch l {
+	case french:
+		switch {
+		case n == 0 || n == 1:
+			return pluralOne // "0 an", "1 an"
+		case n%1000000 == 0:
+			return pluralMany // "1 000 000 de mois"
+		}
+	default:
+		if n == 1 {
+			return pluralOne
+		}
+	}
+	return pluralOther
+}
+
+// Forms of a word for each plural category, the "other" form is used for missing categories.
+type pluralForms map[pluralCategory]string
+
+// Returns the quantity followed by the form of the word matching its plural category (ex: "1,000 months").
+func formatQuantity(l lang, n int, forms map[lang]pluralForms) string {
+	word, ok := forms[l][l.pluralCategory(n)]
+	if !ok {
+		word = forms[l][pluralOther]
+	}
+	return formatInt(l, n) + " " + word
+}
+
+// Abbreviated duration units.
+var (
+	yearUnits = map[lang]pluralForms{
+		english: {pluralOne: "yr", pluralOther: "yrs"},
+		french:  {pluralOne: "an", pluralMany: "d'ans", pluralOther: "ans"},
+	}
+	monthUnits = map[lang]pluralForms{
+		english: {pluralOne: "mo", pluralOther: "mos"},
+		french:  {pluralOne: "mois", pluralMany: "de mois", pluralOther: "mois"},
+	}
+)
+
+// Returns a number of months as years and months (ex: "1 yr 3 mos" or "1 an 3 mois").
+func formatMonths(months int, l lang) string {
+	years, months := months/12, months%12
+	out := []string{}
+	if years > 0 {
+		out = append(out, formatQuantity(l, years, yearUnits))
+	}
+	if months > 0 || years == 0 {
+		out = append(out, formatQuantity(l, months, monthUnits))
+	}
+	return strings.Join(out, " ")
+}
+
+// Digit group and decimal separators (French uses a no-break space to group digits).
+var numberSeparators = map[lang][2]string{
+	english: {",", "."},
+	french:  {"\u00a0", ","},
+}
+
+// Returns the integer with grouped thousands (ex: "12,345" or "12 345").
+func formatInt(l lang, n int) string {
+	return formatDecimal(l, float64(n), 0)
+}
+
+// Returns the number rounded to the given number of decimals (ex: "12,345.6" or "12 345,6").
+func formatDecimal(l lang, f float64, decimals int) string {
+	separators := numberSeparators[l]
+	digits := strconv.FormatFloat(f, 'f', decimals, 64)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	intPart, fracPart, hasFrac := strings.Cut(digits, ".")
+	for i := len(intPart) - 3; i > 0; i -= 3 {
+		intPart = intPart[:i] + separators[0] + intPart[i:]
+	}
+	if hasFrac {
+		return sign + intPart + separators[1] + fracPart
+	}
+	return sign + intPart
+}
+
+var monthNames = map[lang][12]string{
+	english: {"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	french:  {"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+}
+
+// Returns the month and year (ex: "September 2023" or "septembre 2023").
+func formatMonthYear(l lang, t time.Time) string {
+	return monthNames[l][t.Month()-1] + " " + strconv.Itoa(t.Year())
+}
+
+// Returns the full date (ex: "September 1, 2023" or "1er septembre 2023").
+func formatDate(l lang, t time.Time) string {
+	month, day, year := monthNames[l][t.Month()-1], strconv.Itoa(t.Day()), strconv.Itoa(t.Year())
+	switch l {
+	case french:
+		if t.Day() == 1 {
+			day = "1er"
+		}
+		return day + " " + month + " " + year
+	default:
+		return month + " " + day + ", " + year
+	}
+}
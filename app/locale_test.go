@@ -0,0 +1,140 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDate(t *testing.T) {
+	tests := []struct {
+		l    lang
+		t    time.Time
+		want string
+	}{
+		{english, time.Date(2023, time.September, 1, 0, 0, 0, 0, time.UTC), "September 1, 2023"},
+		{english, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), "February 29, 2024"},
+		{french, time.Date(2023, time.September, 1, 0, 0, 0, 0, time.UTC), "1er septembre 2023"},
+		{french, time.Date(2023, time.August, 2, 0, 0, 0, 0, time.UTC), "2 août 2023"},
+		{french, time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC), "31 décembre 2023"},
+	}
+	for _, test := range tests {
+		if got := formatDate(test.l, test.t); got != test.want {
+			t.Errorf("formatDate(%s, %s) = %q, want %q", test.l, test.t.Format("2006-01-02"), got, test.want)
+		}
+	}
+}
+
+func TestFormatMonthYear(t *testing.T) {
+	tests := []struct {
+		l    lang
+		t    time.Time
+		want string
+	}{
+		{english, time.Date(2023, time.January, 31, 0, 0, 0, 0, time.UTC), "January 2023"},
+		{english, time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC), "December 2023"},
+		{french, time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC), "février 2023"},
+		{french, time.Date(2023, time.August, 15, 0, 0, 0, 0, time.UTC), "août 2023"},
+	}
+	for _, test := range tests {
+		if got := formatMonthYear(test.l, test.t); got != test.want {
+			t.Errorf("formatMonthYear(%s, %s) = %q, want %q", test.l, test.t.Format("2006-01-02"), got, test.want)
+		}
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		l        lang
+		f        float64
+		decimals int
+		want     string
+	}{
+		{english, 0, 0, "0"},
+		{english, 999, 0, "999"},
+		{english, 1000, 0, "1,000"},
+		{english, 1234567, 0, "1,234,567"},
+		{english, -12345.678, 1, "-12,345.7"},
+		{french, 999, 0, "999"},
+		{french, 1000, 0, "1\u00a0000"},
+		{french, 1000000, 0, "1\u00a0000\u00a0000"},
+		{french, -12345.678, 1, "-12\u00a0345,7"},
+		{french, 0.5, 2, "0,50"},
+	}
+	for _, test := range tests {
+		if got := formatDecimal(test.l, test.f, test.decimals); got != test.want {
+			t.Errorf("formatDecimal(%s, %v, %d) = %q, want %q", test.l, test.f, test.decimals, got, test.want)
+		}
+	}
+}
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		l    lang
+		n    int
+		want pluralCategory
+	}{
+		{english, 0, pluralOther},
+		{english, 1, pluralOne},
+		{english, -1, pluralOne},
+		{english, 2, pluralOther},
+		{english, 1000000, pluralOther},
+		{french, 0, pluralOne},
+		{french, 1, pluralOne},
+		{french, 2, pluralOther},
+		{french, 1000, pluralOther},
+		{french, 1000000, pluralMany},
+		{french, 2000000, pluralMany},
+		{french, 1000001, pluralOther},
+	}
+	for _, test := range tests {
+		if got := test.l.pluralCategory(test.n); got != test.want {
+			t.Errorf("%s.pluralCategory(%d) = %d, want %d", test.l, test.n, got, test.want)
+		}
+	}
+}
+
+func TestFormatQuantity(t *testing.T) {
+	tests := []struct {
+		l    lang
+		n    int
+		want string
+	}{
+		{english, 0, "0 yrs"},
+		{english, 1, "1 yr"},
+		{english, 2, "2 yrs"},
+		{english, 1000000, "1,000,000 yrs"},
+		{french, 0, "0 an"},
+		{french, 1, "1 an"},
+		{french, 2, "2 ans"},
+		{french, 1000000, "1\u00a0000\u00a0000 d'ans"},
+	}
+	for _, test := range tests {
+		if got := formatQuantity(test.l, test.n, yearUnits); got != test.want {
+			t.Errorf("formatQuantity(%s, %d) = %q, want %q", test.l, test.n, got, test.want)
+		}
+	}
+}
+
+func TestFormatMonths(t *testing.T) {
+	tests := []struct {
+		l      lang
+		months int
+		want   string
+	}{
+		{english, 0, "0 mos"},
+		{english, 1, "1 mo"},
+		{english, 12, "1 yr"},
+		{english, 15, "1 yr 3 mos"},
+		{english, 25, "2 yrs 1 mo"},
+		{french, 0, "0 mois"},
+		{french, 1, "1 mois"},
+		{french, 12, "1 an"},
+		{french, 15, "1 an 3 mois"},
+		{french, 25, "2 ans 1 mois"},
+	}
+	for _, test := range tests {
+		if got := formatMonths(test.months, test.l); got != test.want {
+			t.Errorf("formatMonths(%d, %s) = %q, want %q", test.months, test.l, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Social preview images (used for Open Graph and Twitter cards), drawn with the colors of the website.
+
+// Recommended size of Open Graph images.
+const ogImageWidth, ogImageHeight = 1200, 630
+
+var (
+	ogBackgroundColor = color.RGBA{R: 26, G: 26, B: 26, A: 255}    // --clr-bg
+	ogLogoColor       = color.RGBA{R: 128, G: 255, B: 128, A: 255} // --clr-green
+	ogTextColor       = color.White                                // --clr-txt
+	ogTextDimColor    = color.RGBA{R: 160, G: 160, B: 160, A: 255}
+)
+
+// Returns the URL path of the social preview image of a page (ex: "/og/fr/resume/devops.png").
+func ogImagePath(l lang, path string) string {
+	return "/og/" + string(l) + strings.TrimSuffix(path, "/") + ".png"
+}
+
+// ogImageRenderer draws social preview images with the branding logo and fonts.
+type ogImageRenderer struct {
+	branding    branding
+	regularFont *opentype.Font
+	boldFont    *opentype.Font
+}
+
+func newOGImageRenderer(b branding) (*ogImageRenderer, error) {
+	r := &ogImageRenderer{branding: b}
+	var err error
+	r.regularFont, err = opentype.Parse(mustReadEmbeddedFile(staticFilesFS, "static/"+b.Font+"-Regular.ttf"))
+	if err != nil {
+		return nil, err
+	}
+	r.boldFont, err = opentype.Parse(mustReadEmbeddedFile(staticFilesFS, "static/"+b.Font+"-Bold.ttf"))
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Writes the preview image of a page: the logo and name on top, the page title in the middle
+// and the page URL (without scheme) at the bottom.
+func (r *ogImageRenderer) generatePNG(w io.Writer, title, url string) error {
+	const margin, logoScale = 96, 10
+	img := image.NewRGBA(image.Rect(0, 0, ogImageWidth, ogImageHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(ogBackgroundColor), image.Point{}, draw.Src)
+
+	// Draw logo and name
+	drawLogo(img, r.branding, margin, margin, logoScale)
+	logoWidth, logoHeight := r.branding.LogoWidth*logoScale, r.branding.LogoHeight*logoScale
+	err := r.drawText(img, r.boldFont, 44, ogTextColor, r.branding.Name, margin+logoWidth+40, margin+logoHeight/2+16, ogImageWidth)
+	if err != nil {
+		return err
+	}
+
+	// Draw page title (scaled down if needed to fit the width of the image)
+	err = r.drawText(img, r.boldFont, 96, ogTextColor, title, margin, 400, ogImageWidth-2*margin)
+	if err != nil {
+		return err
+	}
+
+	// Draw URL
+	url = strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	err = r.drawText(img, r.regularFont, 32, ogTextDimColor, url, margin, ogImageHeight-margin, ogImageWidth-2*margin)
+	if err != nil {
+		return err
+	}
+
+	return png.Encode(w, img)
+}
+
+// Draws the text from the given position (on its baseline),
+// the font size (in pixels) is reduced until the text is narrower than the max width.
+func (r *ogImageRenderer) drawText(img draw.Image, f *opentype.Font, size float64, c color.Color, text string, x, y, maxWidth int) error {
+	for {
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return err
+		}
+		d := &font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+		if d.MeasureString(text) > fixed.I(maxWidth) && size > 12 {
+			face.Close()
+			size *= 0.9
+			continue
+		}
+		d.DrawString(text)
+		return face.Close()
+	}
+}
+
+// Draws the logo with its top left corner at the given position, each logo pixel is a square of the given size.
+func drawLogo(img draw.Image, b branding, left, top, scale int) {
+	for _, r := range b.Logo {
+		rect := image.Rect(left+r.X*scale, top+r.Y*scale, left+(r.X+r.W)*scale, top+(r.Y+r.H)*scale)
+		draw.Draw(img, rect, image.NewUniform(ogLogoColor), image.Point{}, draw.Src)
+	}
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// Emails are stored in an outbox in the DB and delivered by a background worker,
+// so that they are not lost when the SMTP server is unavailable.
+// Emails that still can't be delivered after the maximum number of attempts are moved to a dead-letter bucket.
+
+var (
+	boltEmailOutboxBucket      = []byte("email_outbox")
+	boltEmailDeadLettersBucket = []byte("email_dead_letters")
+	boltEmailSentKeysBucket    = []byte("email_sent_keys")    // idempotency keys of delivered emails, with the delivery time
+	boltEmailPendingKeysBucket = []byte("email_pending_keys") // idempotency keys of emails in the outbox, with their outbox key
+)
+
+const (
+	maxEmailDeliveryAttempts = 12
+	emailRetryBaseDelay      = 30 * time.Second // doubled after each failed attempt
+	emailRetryMaxDelay       = time.Hour
+	emailOutboxPollInterval  = 15 * time.Second
+	emailSentKeysRetention   = 24 * time.Hour // an email with the same idempotency key is ignored during this period
+)
+
+// Outbox keys start with the creation time (with a fixed width so that keys sort by creation time).
+const outboxKeyTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+type outboxEmail struct {
+	Key           string // creation time followed by a random ID
+	Email         *Email
+	CreatedAt     time.Time
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+}
+
+type emailOutbox struct {
+	db   *bbolt.DB
+	send Emailer // used to deliver emails
+	wake chan struct{}
+}
+
+func newEmailOutbox(db *bbolt.DB, send Emailer) *emailOutbox {
+	return &emailOutbox{db: db, send: send, wake: make(chan struct{}, 1)}
+}
+
+// Stores the email in the outbox, it is delivered as soon as possible by the background worker.
+// Emails with an explicit idempotency key are ignored if an email with the same key is pending or was recently delivered,
+// emails without one are always sent.
+func (outbox *emailOutbox) enqueue(email *Email) error {
+	now := time.Now()
+	key := now.UTC().Format(outboxKeyTimeLayout) + newID(16)
+	err := outbox.db.Update(func(tx *bbolt.Tx) error {
+		if email.IdempotencyKey != "" {
+			idempotencyKey := []byte(email.IdempotencyKey)
+			if tx.Bucket(boltEmailSentKeysBucket).Get(idempotencyKey) != nil || tx.Bucket(boltEmailPendingKeysBucket).Get(idempotencyKey) != nil {
+				return nil
+			}
+			err := tx.Bucket(boltEmailPendingKeysBucket).Put(idempotencyKey, []byte(key))
+			if err != nil {
+				return err
+			}
+		}
+		entry := &outboxEmail{Key: key, Email: email, CreatedAt: now, NextAttemptAt: now}
+		return tx.Bucket(boltEmailOutboxBucket).Put([]byte(key), mustMarshalJSON(entry))
+	})
+	if err != nil {
+		return fmt.Errorf("store email in outbox: %w", err)
+	}
+	select {
+	case outbox.wake <- struct{}{}:
+	default: // the worker is already going to check the outbox
+	}
+	return nil
+}
+
+// Delivers emails of the outbox when they are enqueued and periodically retries failed ones,
+// all pending emails are sent on startup (regardless of their next attempt time).
+func (outbox *emailOutbox) doDeliver() {
+	outbox.deliverPending(true)
+	ticker := time.NewTicker(emailOutboxPollInterval)
+	for {
+		select {
+		case <-outbox.wake:
+		case <-ticker.C:
+		}
+		outbox.deliverPending(false)
+	}
+}
+
+// Emails are sent in the order in which they were enqueued.
+func (outbox *emailOutbox) deliverPending(all bool) {
+	now := time.Now()
+	pending := []*outboxEmail{}
+	err := outbox.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltEmailOutboxBucket).ForEach(func(k, v []byte) error {
+			entry := &outboxEmail{}
+			mustUnmarshalJSON(v, entry)
+			if all || !entry.NextAttemptAt.After(now) {
+				pending = append(pending, entry)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for _, entry := range pending {
+		err := outbox.deliver(entry)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+	err = outbox.pruneSentKeys(now.Add(-emailSentKeysRetention))
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Sends the email and removes it from the outbox,
+// or schedules the next attempt (moving it to the dead-letter bucket after the last attempt).
+func (outbox *emailOutbox) deliver(entry *outboxEmail) error {
+	sendErr := outbox.send(entry.Email)
+	now := time.Now()
+	return outbox.db.Update(func(tx *bbolt.Tx) error {
+		key := []byte(entry.Key)
+		if sendErr == nil {
+			if entry.Email.IdempotencyKey != "" {
+				err := tx.Bucket(boltEmailSentKeysBucket).Put([]byte(entry.Email.IdempotencyKey), []byte(now.Format(time.RFC3339)))
+				if err != nil {
+					return err
+				}
+				err = tx.Bucket(boltEmailPendingKeysBucket).Delete([]byte(entry.Email.IdempotencyKey))
+				if err != nil {
+					return err
+				}
+			}
+			return tx.Bucket(boltEmailOutboxBucket).Delete(key)
+		}
+
+		entry.Attempts++
+		entry.LastError = sendErr.Error()
+		if entry.Attempts >= maxEmailDeliveryAttempts {
+			log.Printf("email %q to %v moved to dead letters after %d attempts: %s", entry.Email.Subject, entry.Email.To, entry.Attempts, sendErr)
+			err := tx.Bucket(boltEmailDeadLettersBucket).Put(key, mustMarshalJSON(entry))
+			if err != nil {
+				return err
+			}
+			if entry.Email.IdempotencyKey != "" {
+				err = tx.Bucket(boltEmailPendingKeysBucket).Delete([]byte(entry.Email.IdempotencyKey))
+				if err != nil {
+					return err
+				}
+			}
+			return tx.Bucket(boltEmailOutboxBucket).Delete(key)
+		}
+		entry.NextAttemptAt = now.Add(emailRetryDelay(entry.Attempts))
+		log.Printf("email %q to %v not delivered (attempt %d, next one at %s): %s",
+			entry.Email.Subject, entry.Email.To, entry.Attempts, entry.NextAttemptAt.Format(time.RFC3339), sendErr)
+		return tx.Bucket(boltEmailOutboxBucket).Put(key, mustMarshalJSON(entry))
+	})
+}
+
+// Returns the delay before the next attempt, after the given number of failed attempts.
+func emailRetryDelay(attempts int) time.Duration {
+	delay := emailRetryBaseDelay
+	for i := 1; i < attempts && delay < emailRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > emailRetryMaxDelay {
+		delay = emailRetryMaxDelay
+	}
+	return delay
+}
+
+// Removes the idempotency keys of emails delivered before the given time.
+func (outbox *emailOutbox) pruneSentKeys(before time.Time) error {
+	expired := [][]byte{}
+	err := outbox.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltEmailSentKeysBucket).ForEach(func(k, v []byte) error {
+			sentAt, err := time.Parse(time.RFC3339, string(v))
+			if err != nil || sentAt.Before(before) {
+				expired = append(expired, append([]byte{}, k...)) // k is only valid during the transaction
+			}
+			return nil
+		})
+	})
+	if err != nil || len(expired) == 0 {
+		return err
+	}
+	return outbox.db.Update(func(tx *bbolt.Tx) error {
+		for _, k := range expired {
+			err := tx.Bucket(boltEmailSentKeysBucket).Delete(k)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.etcd.io/bbolt"
+)
+
+// Returns an outbox delivering emails to the returned slice.
+func newTestOutbox(t *testing.T, sendErr error) (*emailOutbox, *[]*Email) {
+	t.Helper()
+	sent := []*Email{}
+	outbox := newEmailOutbox(newTestBoltDB(t).f, func(email *Email) error {
+		if sendErr != nil {
+			return sendErr
+		}
+		sent = append(sent, email)
+		return nil
+	})
+	return outbox, &sent
+}
+
+func TestEmailOutboxOrder(t *testing.T) {
+	outbox, sent := newTestOutbox(t, nil)
+	for i := 0; i < 20; i++ {
+		err := outbox.enqueue(&Email{To: []string{"jane@example.com"}, Subject: fmt.Sprint(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	outbox.deliverPending(true)
+	if len(*sent) != 20 {
+		t.Fatalf("got %d emails, want 20", len(*sent))
+	}
+	for i, email := range *sent {
+		if email.Subject != fmt.Sprint(i) {
+			t.Fatalf("email %d has subject %q, emails must be sent in the order in which they were enqueued", i, email.Subject)
+		}
+	}
+}
+
+func TestEmailOutboxIdempotency(t *testing.T) {
+	outbox, sent := newTestOutbox(t, nil)
+	enqueue := func(email Email) {
+		t.Helper()
+		err := outbox.enqueue(&email)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Identical emails without key are all sent
+	report := Email{To: []string{"admin@example.com"}, Subject: "Report", PlainTextBody: "Nothing new"}
+	enqueue(report)
+	enqueue(report)
+
+	// Emails with the same key are only sent once, whether the first one is pending or delivered
+	reply := Email{To: []string{"jane@example.com"}, Subject: "Reply", IdempotencyKey: "reply-1"}
+	enqueue(reply)
+	enqueue(reply)
+	outbox.deliverPending(true)
+	enqueue(reply)
+	outbox.deliverPending(true)
+
+	subjects := []string{}
+	for _, email := range *sent {
+		subjects = append(subjects, email.Subject)
+	}
+	if fmt.Sprint(subjects) != "[Report Report Reply]" {
+		t.Fatalf("got emails %q, want [Report Report Reply]", subjects)
+	}
+
+	// Keys are no longer pending once their email is delivered
+	err := outbox.db.View(func(tx *bbolt.Tx) error {
+		if k, _ := tx.Bucket(boltEmailPendingKeysBucket).Cursor().First(); k != nil {
+			return fmt.Errorf("key %q still pending", k)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEmailOutboxRetry(t *testing.T) {
+	outbox, _ := newTestOutbox(t, errors.New("connection refused"))
+	err := outbox.enqueue(&Email{To: []string{"jane@example.com"}, Subject: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox.deliverPending(true)
+	outbox.deliverPending(false) // the next attempt is not due yet
+
+	entries := []*outboxEmail{}
+	err = outbox.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltEmailOutboxBucket).ForEach(func(k, v []byte) error {
+			entry := &outboxEmail{}
+			mustUnmarshalJSON(v, entry)
+			entries = append(entries, entry)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Attempts != 1 || entries[0].LastError != "connection refused" {
+		t.Fatalf("got outbox entries %+v, want one email with one failed attempt", entries)
+	}
+}
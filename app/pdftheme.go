@@ -0,0 +1,248 @@
+package app
+
+import "strings"
+
+// pdfTheme defines the look and the page layout of the résumé PDF.
+type pdfTheme struct {
+	Name string
+	// Font families embedded in the static files (with "-Regular.ttf" and "-Bold.ttf" variants)
+	BodyFont    string
+	HeadingFont string
+	// Font sizes and spacings (in points)
+	TitleFontSize  float64
+	BigFontSize    float64
+	NormalFontSize float64
+	LineSpacing    float64 // added to the font size to get the line height
+	TitleSpacing   float64 // space around the tag line (relative to the normal font size)
+	FooterSpacing  float64 // space above the source code link (relative to the normal font size)
+	MarginTop      float64
+	MarginSide     float64 // left and right margins
+	// Colors
+	TextColor    [3]int
+	TextDimColor [3]int
+	MidColor     [3]int
+	AccentColor  [3]int
+	// Page layout and page-break strategy
+	SinglePage bool // sizes are scaled down until the résumé fits on one page
+	Layout     func(pdf *resumePDF, content resume, l lang)
+}
+
+// Returns a copy of the theme with all font sizes and line spacings multiplied by the given factor.
+func (theme pdfTheme) scaled(factor float64) pdfTheme {
+	theme.TitleFontSize *= factor
+	theme.BigFontSize *= factor
+	theme.NormalFontSize *= factor
+	theme.LineSpacing *= factor
+	return theme
+}
+
+// The first theme is the default one.
+var pdfThemes = []*pdfTheme{
+	{
+		Name:           "classic",
+		BodyFont:       "Roboto",
+		HeadingFont:    "Roboto",
+		TitleFontSize:  24,
+		BigFontSize:    16,
+		NormalFontSize: 9.5,
+		LineSpacing:    4,
+		TitleSpacing:   2,
+		FooterSpacing:  8,
+		MarginTop:      50,
+		MarginSide:     50,
+		TextColor:      [3]int{0, 0, 0},
+		TextDimColor:   [3]int{50, 50, 50},
+		MidColor:       [3]int{127, 127, 127},
+		AccentColor:    [3]int{50, 50, 50},
+		Layout:         layoutClassicPDF,
+	},
+	{
+		Name:           "sidebar",
+		BodyFont:       "Roboto",
+		HeadingFont:    "JetBrainsMono",
+		TitleFontSize:  20,
+		BigFontSize:    13,
+		NormalFontSize: 9,
+		LineSpacing:    4,
+		TitleSpacing:   1.5,
+		FooterSpacing:  4,
+		MarginTop:      50,
+		MarginSide:     36,
+		TextColor:      [3]int{0, 0, 0},
+		TextDimColor:   [3]int{50, 50, 50},
+		MidColor:       [3]int{110, 110, 110},
+		AccentColor:    [3]int{240, 240, 240},
+		Layout:         layoutSidebarPDF,
+	},
+	{
+		Name:           "compact",
+		BodyFont:       "Roboto",
+		HeadingFont:    "Roboto",
+		TitleFontSize:  18,
+		BigFontSize:    12,
+		NormalFontSize: 8.5,
+		LineSpacing:    3,
+		TitleSpacing:   1,
+		FooterSpacing:  2,
+		MarginTop:      32,
+		MarginSide:     40,
+		TextColor:      [3]int{0, 0, 0},
+		TextDimColor:   [3]int{50, 50, 50},
+		MidColor:       [3]int{127, 127, 127},
+		AccentColor:    [3]int{50, 50, 50},
+		SinglePage:     true,
+		Layout:         layoutCompactPDF,
+	},
+}
+
+var defaultPDFTheme = pdfThemes[0]
+
+// Returns nil if no theme has the given name.
+func findPDFTheme(name string) *pdfTheme {
+	for _, theme := range pdfThemes {
+		if theme.Name == name {
+			return theme
+		}
+	}
+	return nil
+}
+
+// One column layout, experiences are on the first page and other sections on the next one.
+func layoutClassicPDF(pdf *resumePDF, content resume, l lang) {
+	normalFontSize := pdf.theme.NormalFontSize
+
+	pdf.AddPage()
+	pdf.addTitle(content, l)
+	pdf.addExperiences(content, l, 88)
+	pdf.AddPage() // move on to page 2 for other sections
+	pdf.addSkills(content, l)
+	pdf.Ln(3 * normalFontSize)
+	pdf.addLanguages(content, l, 66)
+	pdf.Ln(3 * normalFontSize)
+	pdf.addExternalLinks(content, l)
+	pdf.Ln(3 * normalFontSize)
+	pdf.addContactLinks(content, l)
+}
+
+// Two column layout: name, skills, languages and links in a sidebar on the left of the first page,
+// experiences in the main column (which breaks onto new pages as needed).
+// The sidebar is scaled down until it fits on the first page.
+func layoutSidebarPDF(pdf *resumePDF, content resume, l lang) {
+	const sidebarWidth = 190.0
+	theme := pdf.theme
+
+	// Draw the sidebar background on every page
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFillColor(rgb(theme.AccentColor))
+		pdf.Rect(0, 0, sidebarWidth, a4HeightPt, "F")
+		pdf.SetFillColor(rgb(theme.TextDimColor))
+	})
+	pdf.AddPage()
+
+	// Fill sidebar (without page breaks, so that the main column starts on the same page)
+	sidebarTheme := *theme
+	for sidebarTheme.NormalFontSize > 6 && !sidebarFitsOnPage(content, l, &sidebarTheme, sidebarWidth) {
+		sidebarTheme = sidebarTheme.scaled(0.95)
+	}
+	autoPageBreak, bottomMargin := pdf.GetAutoPageBreak()
+	pdf.SetAutoPageBreak(false, bottomMargin)
+	pdf.theme = &sidebarTheme
+	pdf.SetFontSize(sidebarTheme.NormalFontSize)
+	pdf.addSidebar(content, l, sidebarWidth)
+	pdf.theme = theme
+	pdf.SetFontSize(theme.NormalFontSize)
+	pdf.SetAutoPageBreak(autoPageBreak, bottomMargin)
+
+	// Fill main column (starting back from the top of the first page)
+	pdf.SetLeftMargin(sidebarWidth + theme.MarginSide)
+	pdf.SetRightMargin(theme.MarginSide)
+	pdf.SetXY(sidebarWidth+theme.MarginSide, theme.MarginTop)
+	pdf.addExperiences(content, l, 80)
+}
+
+// Renders the sidebar alone with the given theme to check that it doesn't break onto a second page.
+func sidebarFitsOnPage(content resume, l lang, theme *pdfTheme, width float64) bool {
+	trial := *theme
+	trial.Layout = func(pdf *resumePDF, content resume, l lang) {
+		pdf.AddPage()
+		pdf.addSidebar(content, l, width)
+	}
+	return renderResumePDF(content, l, &trial).PageCount() == 1
+}
+
+func (pdf *resumePDF) addSidebar(content resume, l lang, width float64) {
+	const padding = 22.0
+	theme := pdf.theme
+	normalFontSize := theme.NormalFontSize
+
+	pdf.SetLeftMargin(padding)
+	pdf.SetRightMargin(a4WidthPt - width + padding)
+	pdf.SetXY(padding, theme.MarginTop)
+	pdf.Bookmark(content.Name, 0, -1)
+	pdf.setTempHeadingFont(theme.TitleFontSize, func() {
+		pdf.MultiCell(0, theme.TitleFontSize+theme.LineSpacing, content.Name, "", "", false)
+	})
+	pdf.Ln(theme.TitleSpacing * normalFontSize)
+	pdf.setTempTextColor(theme.TextDimColor, func() {
+		pdf.MultiCell(0, pdf.lineHeight(), content.TagLine[l], "", "", false)
+	})
+	pdf.Ln(3 * normalFontSize)
+	pdf.addSkills(content, l)
+	pdf.Ln(2 * normalFontSize)
+	pdf.addLanguages(content, l, 62)
+	pdf.Ln(2 * normalFontSize)
+	pdf.addStackedLinks(content.ExternalLinksTitle[l], toContactLinks(content.ExternalLinks), l)
+	pdf.Ln(2 * normalFontSize)
+	pdf.addContactLinks(content, l)
+}
+
+// Dense one column layout that fits on a single page.
+func layoutCompactPDF(pdf *resumePDF, content resume, l lang) {
+	normalFontSize := pdf.theme.NormalFontSize
+
+	pdf.AddPage()
+	pdf.addTitle(content, l)
+	pdf.addExperiencesSummary(content, l)
+	pdf.Ln(2 * normalFontSize)
+	pdf.addSection(content.SkillsTitle[l], func() {
+		pdf.Ln(0.5 * normalFontSize)
+		for _, skill := range content.Skills {
+			pdf.Bookmark(skill.Title[l], 2, -1)
+			pdf.addKV(140, skill.Title[l], strings.Join(skill.Tools, ", "), pdf.theme.TextDimColor, pdf.theme.TextDimColor, "B", "")
+		}
+	})
+	pdf.Ln(2 * normalFontSize)
+	pdf.addSection(content.LanguagesTitle[l], func() {
+		pdf.Ln(0.5 * normalFontSize)
+		for _, lang := range content.Languages {
+			pdf.Bookmark(lang.Name[l], 2, -1)
+			pdf.addKV(140, lang.Name[l], lang.Level[l], pdf.theme.TextDimColor, pdf.theme.MidColor, "B", "")
+		}
+	})
+	pdf.Ln(1.5 * normalFontSize)
+	pdf.addLinkRows(content.ExternalLinksTitle[l], toContactLinks(content.ExternalLinks), l, 140)
+	pdf.Ln(2 * normalFontSize)
+	pdf.addLinkRows(content.ContactLinksTitle[l], content.ContactLinks, l, 140)
+}
+
+// Adds experiences with one line for the title and organisation,
+// one for the period, location and technologies and one for the description.
+func (pdf *resumePDF) addExperiencesSummary(content resume, l lang) {
+	normalFontSize := pdf.theme.NormalFontSize
+	pdf.addSection(content.ExperiencesTitle[l], func() {
+		for _, exp := range content.Experiences {
+			pdf.Bookmark(exp.Title[l]+" ("+exp.Company+")", 2, -1)
+			pdf.Ln(0.75 * normalFontSize)
+			pdf.setTempFontStyle("B", func() {
+				pdf.MultiCell(0, pdf.lineHeight(), exp.Title[l]+" · "+exp.Company, "", "", false)
+			})
+			pdf.setTempTextColor(pdf.theme.MidColor, func() {
+				details := content.ExperiencePeriod(exp, l) + " · " + exp.Location + " · " + strings.Join(exp.SkillsAndTools, ", ")
+				pdf.MultiCell(0, pdf.lineHeight(), details, "", "", false)
+			})
+			pdf.setTempTextColor(pdf.theme.TextDimColor, func() {
+				pdf.MultiCell(0, pdf.lineHeight(), exp.Description[l], "", "", false)
+			})
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var pdfFooterRegexp = regexp.MustCompile(`Page (\d+)/(\d+)`)
+
+// Returns the page numbers found in the footers of each page of an uncompressed PDF.
+func pdfFooters(t *testing.T, pdf *resumePDF) [][]string {
+	t.Helper()
+	pdf.SetCompression(false)
+	buf := &bytes.Buffer{}
+	err := pdf.Output(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := strings.ReplaceAll(buf.String(), "\x00", "") // text is encoded in UTF-16
+	pages := strings.Split(text, "/Type /Page\n")[1:]    // the content stream follows each page object
+	out := make([][]string, len(pages))
+	for i, page := range pages {
+		for _, match := range pdfFooterRegexp.FindAllStringSubmatch(page, -1) {
+			out[i] = append(out[i], match[1]+"/"+match[2])
+		}
+	}
+	return out
+}
+
+func TestLayoutSidebarPDF(t *testing.T) {
+	content := resumeData
+	content.RenderTime = time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	longSidebar := content
+	longSidebar.Skills = nil
+	for i := 0; i < 5; i++ {
+		longSidebar.Skills = append(longSidebar.Skills, content.Skills...)
+	}
+	longMainColumn := longSidebar
+	longMainColumn.Experiences = nil
+	for i := 0; i < 2; i++ {
+		longMainColumn.Experiences = append(longMainColumn.Experiences, content.Experiences...)
+	}
+
+	tests := map[string]struct {
+		content resume
+		pages   int
+	}{
+		"default":          {content: content, pages: 1},
+		"long sidebar":     {content: longSidebar, pages: 1},
+		"long main column": {content: longMainColumn, pages: 2},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pdf := renderResumePDF(test.content, english, findPDFTheme("sidebar"))
+			if pdf.PageCount() != test.pages {
+				t.Fatalf("got %d pages, want %d", pdf.PageCount(), test.pages)
+			}
+			footers := pdfFooters(t, pdf)
+			if len(footers) != test.pages {
+				t.Fatalf("found %d pages in the output, want %d", len(footers), test.pages)
+			}
+			for i, got := range footers {
+				want := fmt.Sprintf("%d/%d", i+1, test.pages)
+				if len(got) != 1 || got[0] != want {
+					t.Errorf("page %d: got footers %q, want one %q footer", i+1, got, want)
+				}
+			}
+		})
+	}
+}
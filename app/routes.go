@@ -0,0 +1,131 @@
+package app
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// page is a prerendered HTML page, available in every supported language.
+type page struct {
+	Path     string // URL path without language prefix (ex: "/resume/devops")
+	Template string // ex: "resume.gohtml"
+	TitleKey string // key of the translated page title (ex: "resume.title")
+	Robots   string // content of the "robots" meta tag, pages with "noindex" are excluded from the sitemap
+	Data     any
+}
+
+func (p page) isIndexed() bool { return !strings.Contains(p.Robots, "noindex") }
+
+const (
+	robotsIndex   = "index, follow"
+	robotsNoIndex = "noindex, nofollow"
+)
+
+// pageRoute is a URL pattern of the router and the pages it serves (for the current résumé data).
+type pageRoute struct {
+	Pattern string // ex: "/resume/:variant"
+	Pages   func(content resume) []page
+}
+
+// Pages of the website, the route of each page is registered for every supported language (ex: "/contact" and "/fr/contact").
+var pageRoutes = []pageRoute{
+	{Pattern: "/", Pages: singlePage("/", "home.gohtml", "home.title", robotsIndex)},
+	{Pattern: "/info", Pages: singlePage("/info", "info.gohtml", "info.title", robotsNoIndex)},
+	{Pattern: "/contact", Pages: func(resume) []page { // only prerendered for the sitemap and the social preview image
+		return []page{{Path: "/contact", Template: "contact.gohtml", TitleKey: "contact.title", Robots: robotsIndex, Data: &contactForm{}}}
+	}},
+	{Pattern: "/contact/sent", Pages: singlePage("/contact/sent", "contact_sent.gohtml", "contact.sent_title", robotsNoIndex)},
+	{Pattern: "/resume", Pages: func(content resume) []page {
+		return []page{{Path: resumePagePath("", english), Template: "resume.gohtml", TitleKey: "resume.title", Robots: robotsIndex, Data: content}}
+	}},
+	{Pattern: "/resume/:variant", Pages: func(content resume) []page {
+		out := []page{}
+		for _, v := range content.Variants {
+			version, _ := content.variant(v.Name)
+			out = append(out, page{Path: resumePagePath(v.Name, english), Template: "resume.gohtml", TitleKey: "resume.title", Robots: robotsIndex, Data: version})
+		}
+		return out
+	}},
+}
+
+// Returns the pages of all routes.
+func sitePages(content resume) []page {
+	out := []page{}
+	for _, route := range pageRoutes {
+		out = append(out, route.Pages(content)...)
+	}
+	return out
+}
+
+func singlePage(path, template, titleKey, robots string) func(resume) []page {
+	return func(resume) []page {
+		return []page{{Path: path, Template: template, TitleKey: titleKey, Robots: robots}}
+	}
+}
+
+type sitemapURLSet struct {
+	XMLName    xml.Name     `xml:"urlset"`
+	Namespace  string       `xml:"xmlns,attr"`
+	XHTMLSpace string       `xml:"xmlns:xhtml,attr"`
+	URLs       []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string             `xml:"loc"`
+	LastMod    string             `xml:"lastmod,omitempty"`
+	Alternates []sitemapAlternate `xml:"xhtml:link"`
+}
+
+type sitemapAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// Writes the sitemap of the indexed pages in every language, with links to the other languages of each page.
+// The last modification time of each page is taken from the snapshot.
+func generateSitemap(w io.Writer, pages []page, snap snapshot) error {
+	urlSet := sitemapURLSet{
+		Namespace:  "http://www.sitemaps.org/schemas/sitemap/0.9",
+		XHTMLSpace: "http://www.w3.org/1999/xhtml",
+	}
+	for _, p := range pages {
+		if !p.isIndexed() {
+			continue
+		}
+		alternates := []sitemapAlternate{}
+		for _, l := range supportedLangs {
+			alternates = append(alternates, sitemapAlternate{Rel: "alternate", Hreflang: string(l), Href: defaultBranding.URL + localizedPath(l, p.Path)})
+		}
+		alternates = append(alternates, sitemapAlternate{Rel: "alternate", Hreflang: "x-default", Href: defaultBranding.URL + p.Path})
+		for _, l := range supportedLangs {
+			path := localizedPath(l, p.Path)
+			u := sitemapURL{Loc: defaultBranding.URL + path, Alternates: alternates}
+			if file, ok := snap[path]; ok && !file.ModTime.IsZero() {
+				u.LastMod = file.ModTime.Format(time.RFC3339)
+			}
+			urlSet.URLs = append(urlSet.URLs, u)
+		}
+	}
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	return enc.Encode(urlSet)
+}
+
+// Writes the robots.txt file, allowing all pages and linking to the sitemap.
+// Pages that are not indexed are not disallowed: crawlers must be able to fetch them to see their "noindex" meta tag.
+func generateRobotsTxt(w io.Writer) error {
+	buf := &bytes.Buffer{}
+	buf.WriteString("User-agent: *\nDisallow:\n")
+	fmt.Fprintf(buf, "\nSitemap: %s/sitemap.xml\n", defaultBranding.URL)
+	_, err := buf.WriteTo(w)
+	return err
+}
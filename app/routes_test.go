@@ -0,0 +1,22 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Pages that are not indexed must not be disallowed, otherwise crawlers can't see their "noindex" meta tag.
+func TestGenerateRobotsTxt(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := generateRobotsTxt(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "Disallow: /") {
+		t.Errorf("pages are disallowed in:\n%s", buf)
+	}
+	if !strings.Contains(buf.String(), "Sitemap: "+defaultBranding.URL+"/sitemap.xml\n") {
+		t.Errorf("missing sitemap link in:\n%s", buf)
+	}
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Metadata of pages for search engines and link previews (Open Graph, Twitter cards and JSON-LD structured data).
+
+// Open Graph locales of supported languages.
+var ogLocales = map[lang]string{
+	english: "en_US",
+	french:  "fr_FR",
+}
+
+// Returns the schema.org Person described by the résumé, as JSON-LD.
+// The job titles are the ones of ongoing experiences (or of the latest experience if none is ongoing).
+func personJSONLD(content resume, l lang) map[string]any {
+	jobTitles := []string{}
+	for _, exp := range content.Experiences {
+		if exp.To.IsZero() {
+			jobTitles = append(jobTitles, exp.Title[l])
+		}
+	}
+	if len(jobTitles) == 0 && len(content.Experiences) > 0 {
+		jobTitles = append(jobTitles, content.Experiences[0].Title[l])
+	}
+	sameAs := []string{}
+	for _, link := range content.ExternalLinks {
+		if !isWebsiteURL(link.URL) {
+			sameAs = append(sameAs, link.URL)
+		}
+	}
+	return map[string]any{
+		"@context":    "https://schema.org",
+		"@type":       "Person",
+		"name":        defaultBranding.Name,
+		"url":         defaultBranding.URL + localizedPath(l, "/"),
+		"email":       defaultBranding.ContactEmailAddr,
+		"jobTitle":    jobTitles,
+		"description": content.TagLine[l],
+		"sameAs":      sameAs,
+	}
+}
+
+// Reports whether the URL links to this website (with or without "www.").
+func isWebsiteURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	website, _ := url.Parse(defaultBranding.URL)
+	return err == nil && strings.TrimPrefix(u.Host, "www.") == strings.TrimPrefix(website.Host, "www.")
+}
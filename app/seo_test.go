@@ -0,0 +1,18 @@
+package app
+
+import "testing"
+
+func TestPersonJSONLD(t *testing.T) {
+	person := personJSONLD(resumeData, french)
+	if person["email"] != defaultBranding.ContactEmailAddr {
+		t.Errorf("got email %q, want %q", person["email"], defaultBranding.ContactEmailAddr)
+	}
+	if person["url"] != defaultBranding.URL+"/fr" {
+		t.Errorf("got URL %q, want the French home page", person["url"])
+	}
+	for _, link := range person["sameAs"].([]string) {
+		if isWebsiteURL(link) {
+			t.Errorf("sameAs links to the website itself: %q", link)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Prerendered pages and files, keyed by URL path.
+type snapshot map[string]*prerenderedFile
+
+type prerenderedFile struct {
+	Content     []byte
+	Gzip        []byte // compressed variants of the content, nil when compression does not pay off
+	Brotli      []byte
+	ContentType string
+	Filename    string    // name of the downloaded file, empty for pages displayed in the browser
+	ETag        string    // quoted hash of the content, identical content always has the same ETag
+	ModTime     time.Time // time of the first snapshot in which the content had its current value
+}
+
+func (snap snapshot) add(key string, file *prerenderedFile) {
+	hash := sha256.Sum256(file.Content)
+	file.ETag = `"` + hex.EncodeToString(hash[:16]) + `"`
+	file.Gzip, file.Brotli = compressGzip(file.Content), compressBrotli(file.Content)
+	snap[key] = file
+}
+
+// Responds with the content for the requested URL path (or a 404 error page).
+func (snap snapshot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	file, ok := snap[r.URL.Path]
+	if !ok {
+		respondErrorPage(w, r, http.StatusNotFound, "error.page_not_found")
+		return
+	}
+	file.serve(w, r)
+}
+
+// Responds with the file content in the best encoding accepted by the client,
+// conditional requests (If-None-Match, If-Modified-Since) and range requests are handled by http.ServeContent.
+// Clients must revalidate their cached copy since the website may be reloaded at any time.
+func (file *prerenderedFile) serve(w http.ResponseWriter, r *http.Request) {
+	content, etag := file.Content, file.ETag
+	w.Header().Add("Vary", "Accept-Encoding")
+	switch negotiateEncoding(r.Header.Get("Accept-Encoding"), file.Brotli != nil, file.Gzip != nil) {
+	case "br":
+		content, etag = file.Brotli, strings.TrimSuffix(etag, `"`)+`-br"`
+		w.Header().Set("Content-Encoding", "br")
+	case "gzip":
+		content, etag = file.Gzip, strings.TrimSuffix(etag, `"`)+`-gzip"`
+		w.Header().Set("Content-Encoding", "gzip")
+	}
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, no-cache")
+	if file.Filename != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Filename}))
+	}
+	http.ServeContent(w, r, "", file.ModTime, bytes.NewReader(content))
+}
+
+// site serves the latest successfully rendered snapshot of the website.
+//
+// The snapshot is rebuilt from scratch when the résumé data changes and swapped in atomically,
+// so in-flight requests always see either the previous or the new version of the website.
+type site struct {
+	config  *Config
+	emailer Emailer
+	db      DB
+	now     func() time.Time // clock used as the render date of the résumé
+	state   atomic.Value     // holds a *siteState
+}
+
+// Everything served by the site, replaced as a whole on reload.
+type siteState struct {
+	files       snapshot
+	resume      resume             // used by pages rendered on request
+	contactTmpl *template.Template // the contact page is rendered on request
+}
+
+// Returns nil if the website was not rendered yet.
+func (s *site) currentState() *siteState {
+	state, _ := s.state.Load().(*siteState)
+	return state
+}
+
+func newSite(config *Config, emailer Emailer, db DB) (*site, error) {
+	if len(config.FormSecret) < 16 {
+		return nil, fmt.Errorf("form secret must be at least 16 characters long")
+	}
+	s := &site{config: config, emailer: emailer, db: db, now: time.Now}
+	if config.RenderDate != "" {
+		date, err := time.Parse(renderDateLayout, config.RenderDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid render date %q (expected format is YYYY-MM-DD)", config.RenderDate)
+		}
+		s.now = func() time.Time { return date }
+	}
+	return s, s.reload()
+}
+
+// Layout of the render date pinned in the config (ex: "2023-09-30").
+const renderDateLayout = "2006-01-02"
+
+// Loads the résumé data and renders a new snapshot,
+// the current snapshot is kept if anything goes wrong.
+func (s *site) reload() (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("panic while rendering website: %v", v)
+		}
+	}()
+	content, err := loadResume(s.config.ResumeDataPath)
+	if err != nil {
+		return err
+	}
+	// Only the date is kept so that the same résumé data always renders to the same bytes on a given day
+	now := s.now()
+	content.RenderTime = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	snap, err := renderSnapshot(content)
+	if err != nil {
+		return err
+	}
+	s.setModTimes(snap)
+
+	// Generate the sitemap (which needs the modification time of pages) and the robots.txt file
+	pages := sitePages(content)
+	for path, generate := range map[string]func(io.Writer) error{
+		"/sitemap.xml": func(w io.Writer) error { return generateSitemap(w, pages, snap) },
+		"/robots.txt":  func(w io.Writer) error { return generateRobotsTxt(w) },
+	} {
+		buf := &bytes.Buffer{}
+		err := generate(buf)
+		if err != nil {
+			return fmt.Errorf("generate %q: %w", path, err)
+		}
+		snap.add(path, &prerenderedFile{Content: buf.Bytes(), ContentType: mime.TypeByExtension(filepath.Ext(path))})
+	}
+	s.setModTimes(snap)
+	err = s.db.StoreFileVersions(snap.versions())
+	if err != nil {
+		log.Println(err) // only affects the modification time of files after a restart
+	}
+
+	contactTmpl, err := parseTemplates("contact.gohtml")
+	if err != nil {
+		return err
+	}
+	s.state.Store(&siteState{files: snap, resume: content, contactTmpl: contactTmpl})
+	return nil
+}
+
+// Sets the modification time of new files in the snapshot,
+// unchanged files keep their previous modification time so that Last-Modified only changes with the content.
+// The versions of files are stored in the DB so that restarting the server does not change their modification time either
+// (the content of some files changes with the render date, these files get a new modification time when it does).
+func (s *site) setModTimes(snap snapshot) {
+	now := time.Now().UTC().Truncate(time.Second)
+	previous := s.fileVersions()
+	for key, file := range snap {
+		switch old, ok := previous[key]; {
+		case !file.ModTime.IsZero():
+			continue
+		case ok && old.ETag == file.ETag:
+			file.ModTime = old.ModTime
+		default:
+			file.ModTime = now
+		}
+	}
+}
+
+// Version of a prerendered file, kept across restarts.
+type fileVersion struct {
+	ETag    string
+	ModTime time.Time
+}
+
+func (snap snapshot) versions() map[string]fileVersion {
+	out := make(map[string]fileVersion, len(snap))
+	for key, file := range snap {
+		out[key] = fileVersion{ETag: file.ETag, ModTime: file.ModTime}
+	}
+	return out
+}
+
+// Returns the versions of the files of the current snapshot,
+// or the versions stored in the DB when no snapshot was rendered yet (on startup).
+func (s *site) fileVersions() map[string]fileVersion {
+	if state := s.currentState(); state != nil {
+		return state.files.versions()
+	}
+	versions, err := s.db.GetFileVersions()
+	if err != nil {
+		log.Println(err)
+	}
+	return versions
+}
+
+func renderSnapshot(content resume) (snapshot, error) {
+	snap := snapshot{}
+	ogImages, err := newOGImageRenderer(defaultBranding)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range sitePages(content) {
+		for _, l := range supportedLangs {
+			path := localizedPath(l, p.Path)
+			buf := &bytes.Buffer{}
+			err := prerenderPage(buf, p, l, content)
+			if err != nil {
+				return nil, fmt.Errorf("render page %q: %w", path, err)
+			}
+			snap.add(path, &prerenderedFile{Content: buf.Bytes(), ContentType: htmlContentType})
+
+			// Render the social preview image of the page
+			imgPath := ogImagePath(l, p.Path)
+			title, err := translate(l, p.TitleKey)
+			if err != nil {
+				return nil, err
+			}
+			buf = &bytes.Buffer{}
+			err = ogImages.generatePNG(buf, title, defaultBranding.URL+path)
+			if err != nil {
+				return nil, fmt.Errorf("generate %q: %w", imgPath, err)
+			}
+			snap.add(imgPath, &prerenderedFile{Content: buf.Bytes(), ContentType: "image/png"})
+		}
+	}
+
+	// Render résumé files (other formats than HTML and PDF are only available for the full résumé)
+	for _, l := range supportedLangs {
+		for _, format := range resumeFileFormats {
+			path := resumeFilePath("", l, format.ext)
+			buf := &bytes.Buffer{}
+			err := format.generate(buf, content, l)
+			if err != nil {
+				return nil, fmt.Errorf("generate %q: %w", path, err)
+			}
+			snap.add(path, &prerenderedFile{
+				Content:     buf.Bytes(),
+				ContentType: format.contentType,
+				Filename:    content.downloadFilename(l, format.ext),
+			})
+		}
+	}
+
+	// Render résumé PDFs for the full résumé and each variant
+	versions := []resume{content}
+	for _, v := range content.Variants {
+		version, _ := content.variant(v.Name)
+		versions = append(versions, version)
+	}
+	for _, version := range versions {
+		for _, l := range supportedLangs {
+			for _, theme := range pdfThemes {
+				path := resumeFilePath(version.Variant, l, ".pdf")
+				buf := &bytes.Buffer{}
+				err := generateResumePDF(buf, version, l, theme)
+				if err != nil {
+					return nil, fmt.Errorf("generate %q with theme %q: %w", path, theme.Name, err)
+				}
+				snap.add(resumePDFKey(path, theme), &prerenderedFile{
+					Content:     buf.Bytes(),
+					ContentType: "application/pdf",
+					Filename:    version.downloadFilename(l, ".pdf"),
+				})
+			}
+		}
+	}
+	return snap, nil
+}
+
+const htmlContentType = "text/html; charset=utf-8"
+
+// Downloadable résumé formats (other than PDF).
+var resumeFileFormats = []struct {
+	ext         string
+	contentType string
+	generate    func(io.Writer, resume, lang) error
+}{
+	{ext: ".json", contentType: "application/json; charset=utf-8", generate: generateJSONResume},
+	{ext: ".txt", contentType: "text/plain; charset=utf-8", generate: generateResumeText},
+	{ext: ".md", contentType: "text/markdown; charset=utf-8", generate: generateResumeMarkdown},
+	{ext: ".docx", contentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", generate: generateResumeDOCX},
+}
+
+// Responds with the prerendered content for the requested URL path.
+func (s *site) servePrerendered(w http.ResponseWriter, r *http.Request) {
+	s.currentState().files.ServeHTTP(w, r)
+}
+
+// Permanently redirects to the résumé page of the variant captured in the URL path, in the given language.
+func redirectToResumePage(l lang) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, resumePagePath(r.URL.Query().Get(":variant"), l), http.StatusMovedPermanently)
+	})
+}
+
+// Responds with the prerendered résumé PDF in the theme requested in the URL query (ex: "?theme=compact").
+func (s *site) serveResumePDF(w http.ResponseWriter, r *http.Request) {
+	theme := defaultPDFTheme
+	if name := r.URL.Query().Get("theme"); name != "" {
+		theme = findPDFTheme(name)
+	}
+	file, ok := s.currentState().files[resumePDFKey(r.URL.Path, theme)]
+	if theme == nil || !ok {
+		respondErrorPage(w, r, http.StatusNotFound, "error.resume_not_found")
+		return
+	}
+	file.serve(w, r)
+}
+
+// Returns the snapshot key of a résumé PDF rendered with the given theme.
+func resumePDFKey(path string, theme *pdfTheme) string {
+	if theme == nil {
+		return ""
+	}
+	return path + "?theme=" + theme.Name
+}
+
+// Re-renders the website when the résumé data file is modified (checked every few seconds),
+// when the process receives a SIGHUP signal, or when a new month starts (to update the duration of ongoing experiences).
+// The admin is notified by email when the new version of the website could not be rendered.
+func (s *site) doReloadOnChange() {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	ticker := time.NewTicker(5 * time.Second)
+	lastModTime := fileModTime(s.config.ResumeDataPath)
+	lastMonth := s.now().Month()
+	for {
+		select {
+		case <-sighup:
+			log.Println("received SIGHUP, reloading website")
+		case <-ticker.C:
+			modTime, month := fileModTime(s.config.ResumeDataPath), s.now().Month()
+			switch {
+			default:
+				continue
+			case !modTime.Equal(lastModTime):
+				log.Println("resume data file changed, reloading website")
+			case month != lastMonth:
+				log.Println("new month, reloading website")
+			}
+			lastModTime, lastMonth = modTime, month
+		}
+		err := s.reload()
+		if err != nil {
+			log.Println(err)
+			err = sendEmailToAdmin(s.config, s.emailer, "Website reload failed", err.Error())
+			if err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+// Returns the zero time if the file does not exist (or if no path is provided).
+func fileModTime(fpath string) time.Time {
+	if fpath == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(fpath)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+// Restarting the server must not change the modification time of files, only changing their content does.
+func TestSetModTimes(t *testing.T) {
+	db := newTestBoltDB(t)
+	s := &site{db: db}
+	snap := snapshot{"/": &prerenderedFile{ETag: `"a"`}}
+	s.setModTimes(snap)
+	if time.Since(snap["/"].ModTime) > time.Minute {
+		t.Fatalf("got modification time %s for a new file, want the current time", snap["/"].ModTime)
+	}
+
+	// Simulates a restart some time after the file was rendered
+	modTime := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	err := db.StoreFileVersions(map[string]fileVersion{"/": {ETag: `"a"`, ModTime: modTime}, "/fr": {ETag: `"b"`, ModTime: modTime}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = &site{db: db}
+	snap = snapshot{"/": &prerenderedFile{ETag: `"a"`}, "/fr": &prerenderedFile{ETag: `"c"`}}
+	s.setModTimes(snap)
+	if !snap["/"].ModTime.Equal(modTime) {
+		t.Fatalf("got modification time %s for unchanged content after a restart, want %s", snap["/"].ModTime, modTime)
+	}
+	if time.Since(snap["/fr"].ModTime) > time.Minute {
+		t.Fatalf("got modification time %s for content changed during a restart, want the current time", snap["/fr"].ModTime)
+	}
+	s.state.Store(&siteState{files: snap})
+
+	unchanged := snapshot{"/": &prerenderedFile{ETag: `"a"`}}
+	s.setModTimes(unchanged)
+	if !unchanged["/"].ModTime.Equal(modTime) {
+		t.Fatalf("got modification time %s for unchanged content, want %s", unchanged["/"].ModTime, modTime)
+	}
+	changed := snapshot{"/": &prerenderedFile{ETag: `"d"`}}
+	s.setModTimes(changed)
+	if time.Since(changed["/"].ModTime) > time.Minute {
+		t.Fatalf("got modification time %s for changed content, want the current time", changed["/"].ModTime)
+	}
+}
+
+func TestStoreFileVersions(t *testing.T) {
+	db := newTestBoltDB(t)
+	modTime := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for _, versions := range []map[string]fileVersion{
+		{"/": {ETag: `"a"`, ModTime: modTime}, "/removed": {ETag: `"b"`, ModTime: modTime}},
+		{"/": {ETag: `"c"`, ModTime: modTime}},
+	} {
+		err := db.StoreFileVersions(versions)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := db.GetFileVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got["/"].ETag != `"c"` || !got["/"].ModTime.Equal(modTime) {
+		t.Fatalf("got versions %v, want only the last stored ones", got)
+	}
+}
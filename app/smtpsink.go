@@ -0,0 +1,283 @@
+package app
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"html/template"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// smtpSink is an in-process SMTP server used in development,
+// it accepts all emails (with any credentials) and keeps them in memory instead of delivering them.
+// Emails are sent to it with the real SMTP emailer, and displayed on the "/_dev/mail" page.
+type smtpSink struct {
+	listener  net.Listener
+	tlsConfig *tls.Config // optional, enables the STARTTLS extension
+	mu        sync.Mutex
+	messages  []*sinkMessage
+}
+
+type sinkMessage struct {
+	ReceivedAt time.Time
+	From       string // envelope sender and recipients
+	To         []string
+	Subject    string // decoded
+	Header     mail.Header
+	Parts      []sinkPart // leaf parts of the MIME message, with decoded content
+	Raw        string
+	Err        string // set if the message could not be parsed
+}
+
+type sinkPart struct {
+	ContentType string
+	Filename    string // set for attachments
+	IsHTML      bool
+	Content     string
+}
+
+// Starts listening on the given address (ex: "localhost:2525", or "localhost:0" for a random port).
+func newSMTPSink(addr string) (*smtpSink, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	sink := &smtpSink{listener: listener}
+	go sink.serve()
+	return sink, nil
+}
+
+func mustStartSMTPSink(addr string) *smtpSink {
+	sink, err := newSMTPSink(addr)
+	if err != nil {
+		panic(err)
+	}
+	log.Println("SMTP sink listening at address", sink.addr())
+	return sink
+}
+
+func (sink *smtpSink) addr() string { return sink.listener.Addr().String() }
+
+// Returns a copy of the config with the SMTP server set to the sink.
+func (sink *smtpSink) config(config *Config) *Config {
+	out := *config
+	host, port, _ := net.SplitHostPort(sink.addr())
+	out.SMTPHost = host
+	out.SMTPPort, _ = strconv.Atoi(port)
+	out.SMTPTLSMode = smtpTLSNone
+	out.EmailTransport = "smtp"
+	return &out
+}
+
+func (sink *smtpSink) close() error { return sink.listener.Close() }
+
+// Returns the received messages, the latest first.
+func (sink *smtpSink) received() []*sinkMessage {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	out := make([]*sinkMessage, 0, len(sink.messages))
+	for i := len(sink.messages) - 1; i >= 0; i-- {
+		out = append(out, sink.messages[i])
+	}
+	return out
+}
+
+func (sink *smtpSink) serve() {
+	for {
+		conn, err := sink.listener.Accept()
+		if err != nil {
+			return // listener closed
+		}
+		go func() {
+			err := sink.handle(conn)
+			if err != nil {
+				log.Println("SMTP sink:", err)
+			}
+		}()
+	}
+}
+
+// Handles an SMTP session, only the commands used by net/smtp clients are supported.
+func (sink *smtpSink) handle(conn net.Conn) error {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Minute))
+	tp := textproto.NewConn(conn)
+	err := tp.PrintfLine("220 localhost SMTP sink ready")
+	tlsStarted := false
+	var from string
+	var to []string
+	for err == nil {
+		var line string
+		line, err = tp.ReadLine()
+		if err != nil {
+			break
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			extensions := "250-8BITMIME\r\n"
+			if sink.tlsConfig != nil && !tlsStarted {
+				extensions += "250-STARTTLS\r\n"
+			}
+			err = tp.PrintfLine("250-localhost\r\n" + extensions + "250 AUTH PLAIN")
+		case "STARTTLS":
+			if sink.tlsConfig == nil || tlsStarted {
+				err = tp.PrintfLine("502 5.5.2 Command not implemented")
+				continue
+			}
+			err = tp.PrintfLine("220 2.0.0 Ready to start TLS")
+			if err != nil {
+				break
+			}
+			// The session starts over on the encrypted connection
+			conn = tls.Server(conn, sink.tlsConfig)
+			tp = textproto.NewConn(conn)
+			from, to, tlsStarted = "", nil, true
+		case "HELO":
+			err = tp.PrintfLine("250 localhost")
+		case "AUTH":
+			err = tp.PrintfLine("235 2.7.0 Authentication successful")
+		case "MAIL":
+			from, to = smtpPathArg(arg), nil
+			err = tp.PrintfLine("250 OK")
+		case "RCPT":
+			to = append(to, smtpPathArg(arg))
+			err = tp.PrintfLine("250 OK")
+		case "DATA":
+			if from == "" || len(to) == 0 {
+				err = tp.PrintfLine("503 5.5.1 MAIL and RCPT commands are required first")
+				continue
+			}
+			err = tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			if err != nil {
+				break
+			}
+			var raw []byte
+			raw, err = io.ReadAll(tp.DotReader())
+			if err != nil {
+				break
+			}
+			sink.store(from, to, raw)
+			from, to = "", nil
+			err = tp.PrintfLine("250 OK")
+		case "RSET":
+			from, to = "", nil
+			err = tp.PrintfLine("250 OK")
+		case "NOOP":
+			err = tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return nil
+		default:
+			err = tp.PrintfLine("502 5.5.2 Command not implemented")
+		}
+	}
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// Returns the address of a MAIL or RCPT command argument (ex: "FROM:<jane@example.com> BODY=8BITMIME").
+func smtpPathArg(arg string) string {
+	_, path, _ := strings.Cut(arg, "<")
+	path, _, _ = strings.Cut(path, ">")
+	return path
+}
+
+func (sink *smtpSink) store(from string, to []string, raw []byte) {
+	msg := &sinkMessage{ReceivedAt: time.Now(), From: from, To: to, Raw: string(raw)}
+	err := msg.parse()
+	if err != nil {
+		msg.Err = err.Error()
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.messages = append(sink.messages, msg)
+}
+
+func (msg *sinkMessage) parse() error {
+	parsed, err := mail.ReadMessage(strings.NewReader(msg.Raw))
+	if err != nil {
+		return err
+	}
+	msg.Header = parsed.Header
+	msg.Subject, err = new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		return err
+	}
+	msg.Parts, err = parseMIMEParts(textproto.MIMEHeader(parsed.Header), parsed.Body)
+	return err
+}
+
+// Returns the leaf parts of the given MIME entity.
+func parseMIMEParts(header textproto.MIMEHeader, body io.Reader) ([]sinkPart, error) {
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		out := []sinkPart{}
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart() // quoted-printable content is decoded by the multipart reader
+			if err == io.EOF {
+				return out, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			parts, err := parseMIMEParts(part.Header, part)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, parts...)
+		}
+	}
+
+	switch strings.ToLower(header.Get("Content-Transfer-Encoding")) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	part := sinkPart{ContentType: contentType, IsHTML: mediaType == "text/html", Content: string(content)}
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		part.Filename = params["filename"]
+	}
+	return []sinkPart{part}, nil
+}
+
+var devMailTmpl = template.Must(template.ParseFS(uiFS, "ui/_dev_mail.gohtml"))
+
+// Displays the received messages (for development only).
+func (sink *smtpSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", htmlContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	err := devMailTmpl.ExecuteTemplate(w, "dev_mail", map[string]any{
+		"Addr":     sink.addr(),
+		"Messages": sink.received(),
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
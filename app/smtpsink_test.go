@@ -0,0 +1,72 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSMTPSink(t *testing.T) {
+	sink, err := newSMTPSink("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := sink.config(&Config{SMTPSender: "Website <website@example.com>", SMTPUsername: "user", SMTPPassword: "secret"})
+	send, err := newSMTPEmailer(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, subject := range []string{"Première", "Seconde"} { // the second email reuses the connection
+		err = send(&Email{
+			From:          config.SMTPSender,
+			To:            []string{"Jane <jane@example.com>", "john@example.org"},
+			Subject:       subject,
+			PlainTextBody: "Hello",
+			HTMLBody:      "<p>Hello</p>",
+			Attachments:   []emailAttachment{{Filename: "résumé.pdf", ContentType: "application/pdf", Content: []byte("%PDF-1.4")}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	received := sink.received()
+	if len(received) != 2 {
+		t.Fatalf("got %d messages, want 2", len(received))
+	}
+	msg := received[0] // latest first
+	if msg.Err != "" {
+		t.Fatalf("message could not be parsed: %s", msg.Err)
+	}
+	if msg.Subject != "Seconde" {
+		t.Errorf("got subject %q, want %q", msg.Subject, "Seconde")
+	}
+	if msg.From != "website@example.com" || len(msg.To) != 2 || msg.To[0] != "jane@example.com" || msg.To[1] != "john@example.org" {
+		t.Errorf("got envelope from %q to %q", msg.From, msg.To)
+	}
+	if len(msg.Parts) != 3 {
+		t.Fatalf("got %d parts, want 3", len(msg.Parts))
+	}
+	if msg.Parts[0].IsHTML || strings.TrimSpace(msg.Parts[0].Content) != "Hello" {
+		t.Errorf("got plain text part %+v", msg.Parts[0])
+	}
+	if !msg.Parts[1].IsHTML || strings.TrimSpace(msg.Parts[1].Content) != "<p>Hello</p>" {
+		t.Errorf("got HTML part %+v", msg.Parts[1])
+	}
+	if msg.Parts[2].Filename != "résumé.pdf" || msg.Parts[2].Content != "%PDF-1.4" {
+		t.Errorf("got attachment %+v", msg.Parts[2])
+	}
+
+	// No new connections are accepted once the sink is closed
+	err = sink.close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	send, err = newSMTPEmailer(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = send(&Email{From: config.SMTPSender, To: []string{"jane@example.com"}, Subject: "Closed", PlainTextBody: "Hello"})
+	if err == nil {
+		t.Fatal("sending to a closed sink succeeded")
+	}
+}
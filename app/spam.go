@@ -0,0 +1,115 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spam protection of forms, without JavaScript nor third-party services:
+// a honeypot field, a signed time-to-submit token, a rate limit per visitor and a content score.
+
+// Name of the form field that is hidden to humans, bots filling it in are rejected.
+const honeypotFieldName = "website"
+
+// Humans take some time to fill in a form, and forms left open for too long must be reloaded.
+const (
+	minTimeToSubmit = 3 * time.Second
+	maxTimeToSubmit = 2 * time.Hour
+)
+
+// Maximum number of messages accepted from the same visitor within the rate limit period.
+const (
+	maxSubmissionsPerVisitor  = 3
+	submissionRateLimitPeriod = time.Hour
+)
+
+// Maximum number of automatic replies sent to the same email address within the rate limit period,
+// the address is not verified so replies must not be usable to flood someone's mailbox.
+const (
+	maxRepliesPerRecipient = 1
+	replyRateLimitPeriod   = 24 * time.Hour
+)
+
+var (
+	errFormTokenInvalid = errors.New("invalid form token")
+	errFormTooFast      = errors.New("form submitted too fast")
+	errFormTokenExpired = errors.New("form token expired")
+)
+
+// Returns a token holding the time at which the form was displayed (ex: "1696118400.3f2a..."),
+// signed with the given key so that it can't be forged.
+func newFormToken(key []byte, form string, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return timestamp + "." + formTokenSignature(key, form, timestamp)
+}
+
+// Returns an error if the token was not issued for the form or if it was not submitted in the allowed time frame.
+func checkFormToken(key []byte, form, token string, now time.Time) error {
+	timestamp, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(formTokenSignature(key, form, timestamp))) {
+		return errFormTokenInvalid
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errFormTokenInvalid
+	}
+	switch elapsed := now.Sub(time.Unix(unix, 0)); {
+	case elapsed < minTimeToSubmit:
+		return errFormTooFast
+	case elapsed > maxTimeToSubmit:
+		return errFormTokenExpired
+	}
+	return nil
+}
+
+func formTokenSignature(key []byte, form, timestamp string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(form + ":" + timestamp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Submissions with a score above this are considered spam.
+const maxSpamScore = 2
+
+// Words that are rarely found in legitimate messages sent to a developer (in lowercase).
+var spamBlocklist = []string{
+	"backlinks",
+	"casino",
+	"cialis",
+	"forex",
+	"payday loan",
+	"porn",
+	"seo services",
+	"viagra",
+	"web traffic",
+}
+
+var linkRegexp = regexp.MustCompile(`(?i)https?://|www\.`)
+
+// Returns a score that grows with the number of links and blocklisted words found in the given texts.
+func spamScore(texts ...string) int {
+	score := 0
+	for _, text := range texts {
+		text = strings.ToLower(text)
+		score += len(linkRegexp.FindAllStringIndex(text, -1))
+		for _, word := range spamBlocklist {
+			score += 2 * strings.Count(text, word)
+		}
+	}
+	return score
+}
+
+// Submission rejected by the spam protection, they are stored to be counted in health reports.
+type rejectedSubmission struct {
+	ID          string
+	CreatedAt   time.Time
+	Form        string
+	Reason      string // ex: "honeypot", "too_fast", "rate_limited"
+	VisitorHash string
+}
@@ -0,0 +1,240 @@
+package app
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Email transports, selected with the "email_transport" config field:
+//   - "smtp" (default): sent to the SMTP server of the config
+//   - "sendmail": piped to a sendmail-compatible binary
+//   - "file": written to a maildir directory (as ".eml" files), for hosts without network access
+//
+// Messages are signed with DKIM if it is configured.
+func newEmailTransport(config *Config) (Emailer, error) {
+	signer, err := loadDKIMSigner(config)
+	if err != nil {
+		return nil, err
+	}
+	switch config.EmailTransport {
+	case "", "smtp":
+		return newSMTPEmailer(config, signer)
+	case "sendmail":
+		path := config.SendmailPath
+		if path == "" {
+			path = "/usr/sbin/sendmail"
+		}
+		return newSendmailEmailer(path, signer), nil
+	case "file":
+		if config.EmailDropDir == "" {
+			return nil, fmt.Errorf("missing email drop directory for file email transport")
+		}
+		return newFileDropEmailer(config.EmailDropDir, signer)
+	default:
+		return nil, fmt.Errorf("unknown email transport %q (expected smtp, sendmail or file)", config.EmailTransport)
+	}
+}
+
+// Returns the message string of the email, signed if a DKIM signer is given.
+func formatEmail(email *Email, signer *dkimSigner) (string, error) {
+	msg := emailMessageStr(email)
+	if signer == nil {
+		return msg, nil
+	}
+	return signer.sign(msg, time.Now())
+}
+
+// TLS modes of the SMTP transport, set with the "smtp_tls_mode" config field.
+const (
+	smtpTLSStartTLS = "starttls" // default, the connection is upgraded to TLS (and fails if the server doesn't support it)
+	smtpTLSImplicit = "implicit" // TLS from the start of the connection (usually on port 465)
+	smtpTLSNone     = "none"     // only allowed for local servers since credentials are sent in clear text
+)
+
+// The connection to the SMTP server is kept open and reused for the next emails,
+// a new connection is opened if the server closed it in the meantime.
+type smtpTransport struct {
+	host    string
+	addr    string
+	tlsMode string
+	auth    smtp.Auth
+	signer  *dkimSigner
+	rootCAs *x509.CertPool // used to verify the server certificate, nil to use the system roots
+	mu      sync.Mutex
+	client  *smtp.Client // nil when disconnected
+}
+
+func newSMTPEmailer(config *Config, signer *dkimSigner) (Emailer, error) {
+	t, err := newSMTPTransport(config, signer)
+	if err != nil {
+		return nil, err
+	}
+	sender := envelopeAddress(config.SMTPSender)
+	return func(email *Email) error { return t.send(sender, email) }, nil
+}
+
+func newSMTPTransport(config *Config, signer *dkimSigner) (*smtpTransport, error) {
+	t := &smtpTransport{
+		signer:  signer,
+		host:    config.SMTPHost,
+		addr:    net.JoinHostPort(config.SMTPHost, strconv.Itoa(config.SMTPPort)),
+		tlsMode: config.SMTPTLSMode,
+	}
+	switch t.tlsMode {
+	case "":
+		t.tlsMode = smtpTLSStartTLS
+	case smtpTLSStartTLS, smtpTLSImplicit, smtpTLSNone:
+	default:
+		return nil, fmt.Errorf("unknown SMTP TLS mode %q (expected starttls, implicit or none)", t.tlsMode)
+	}
+	if config.SMTPUsername != "" {
+		t.auth = smtp.PlainAuth("", config.SMTPUsername, config.SMTPPassword, config.SMTPHost)
+	}
+	return t, nil
+}
+
+func (t *smtpTransport) send(sender string, email *Email) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Reuse the current connection if it is still alive
+	if t.client != nil && t.client.Noop() != nil {
+		t.client.Close()
+		t.client = nil
+	}
+	if t.client == nil {
+		client, err := t.dial()
+		if err != nil {
+			return err
+		}
+		t.client = client
+	}
+
+	err := t.sendWithClient(sender, email)
+	if err != nil {
+		// Start from a new connection for the next email since the session may be in an unknown state
+		t.client.Close()
+		t.client = nil
+		return fmt.Errorf("send email with SMTP: %w", err)
+	}
+	return nil
+}
+
+func (t *smtpTransport) dial() (*smtp.Client, error) {
+	const timeout = 10 * time.Second
+	tlsConfig := &tls.Config{ServerName: t.host, RootCAs: t.rootCAs}
+	var conn net.Conn
+	var err error
+	if t.tlsMode == smtpTLSImplicit {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", t.addr, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", t.addr, timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect to SMTP server: %w", err)
+	}
+	client, err := smtp.NewClient(conn, t.host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connect to SMTP server: %w", err)
+	}
+
+	err = client.Hello("localhost")
+	if err == nil && t.tlsMode == smtpTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			err = fmt.Errorf("SMTP server does not support STARTTLS")
+		} else {
+			err = client.StartTLS(tlsConfig)
+		}
+	}
+	if err == nil && t.auth != nil {
+		err = client.Auth(t.auth)
+	}
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("open SMTP session: %w", err)
+	}
+	return client, nil
+}
+
+func (t *smtpTransport) sendWithClient(sender string, email *Email) error {
+	msg, err := formatEmail(email, t.signer)
+	if err != nil {
+		return err
+	}
+	err = t.client.Mail(sender)
+	if err != nil {
+		return err
+	}
+	for _, recipient := range email.To {
+		err = t.client.Rcpt(envelopeAddress(recipient))
+		if err != nil {
+			return err
+		}
+	}
+	w, err := t.client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write([]byte(msg))
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// Pipes the message to the sendmail binary at the given path (recipients are passed as arguments).
+func newSendmailEmailer(path string, signer *dkimSigner) Emailer {
+	return func(email *Email) error {
+		msg, err := formatEmail(email, signer)
+		if err != nil {
+			return err
+		}
+		args := []string{"-i", "-f", envelopeAddress(email.From), "--"}
+		for _, recipient := range email.To {
+			args = append(args, envelopeAddress(recipient))
+		}
+		cmd := exec.Command(path, args...)
+		cmd.Stdin = strings.NewReader(strings.ReplaceAll(msg, "\r\n", "\n")) // sendmail expects local line endings
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("run sendmail: %w: %s", err, bytes.TrimSpace(output))
+		}
+		return nil
+	}
+}
+
+// Writes messages in the "new" directory of the given maildir,
+// files are first written in the "tmp" directory so that readers never see partial files.
+func newFileDropEmailer(dir string, signer *dkimSigner) (Emailer, error) {
+	for _, subdir := range []string{"tmp", "new", "cur"} {
+		err := os.MkdirAll(filepath.Join(dir, subdir), 0o750)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return func(email *Email) error {
+		msg, err := formatEmail(email, signer)
+		if err != nil {
+			return err
+		}
+		name := strconv.FormatInt(time.Now().UnixNano(), 10) + "." + newID(8) + ".eml"
+		tmpPath := filepath.Join(dir, "tmp", name)
+		err = os.WriteFile(tmpPath, []byte(msg), 0o640)
+		if err != nil {
+			return fmt.Errorf("write email file: %w", err)
+		}
+		return os.Rename(tmpPath, filepath.Join(dir, "new", name))
+	}, nil
+}
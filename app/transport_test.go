@@ -0,0 +1,235 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Returns a self-signed certificate for 127.0.0.1 and a pool to verify it.
+func newTestCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+// Counts accepted connections.
+type countingListener struct {
+	net.Listener
+	count int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		atomic.AddInt32(&l.count, 1)
+	}
+	return conn, err
+}
+
+// Starts an SMTP sink serving the given TLS mode.
+func newTestSMTPSink(t *testing.T, tlsMode string) (*smtpSink, *countingListener, *x509.CertPool) {
+	t.Helper()
+	cert, pool := newTestCertificate(t)
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := &countingListener{Listener: tcp}
+	sink := &smtpSink{listener: listener}
+	switch tlsMode {
+	case smtpTLSStartTLS:
+		sink.tlsConfig = tlsConfig
+	case smtpTLSImplicit:
+		sink.listener = tls.NewListener(listener, tlsConfig)
+	}
+	go sink.serve()
+	t.Cleanup(func() { sink.close() })
+	return sink, listener, pool
+}
+
+func TestSMTPTransport(t *testing.T) {
+	for _, tlsMode := range []string{smtpTLSNone, smtpTLSStartTLS, smtpTLSImplicit} {
+		t.Run(tlsMode, func(t *testing.T) {
+			sink, listener, pool := newTestSMTPSink(t, tlsMode)
+			config := sink.config(&Config{SMTPSender: "website@example.com", SMTPUsername: "user", SMTPPassword: "secret"})
+			config.SMTPTLSMode = tlsMode
+			transport, err := newSMTPTransport(config, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			transport.rootCAs = pool
+
+			for _, subject := range []string{"First", "Second", "Third"} {
+				err := transport.send(config.SMTPSender, &Email{From: config.SMTPSender, To: []string{"jane@example.com"}, Subject: subject, PlainTextBody: "Hello"})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			received := sink.received()
+			if len(received) != 3 || received[0].Subject != "Third" {
+				t.Fatalf("got %d messages, want 3", len(received))
+			}
+			if count := atomic.LoadInt32(&listener.count); count != 1 {
+				t.Fatalf("got %d connections, want 1 (the connection must be reused)", count)
+			}
+		})
+	}
+}
+
+func TestSMTPTransportRequiresTLS(t *testing.T) {
+	sink, _, pool := newTestSMTPSink(t, smtpTLSNone) // doesn't support STARTTLS
+	config := sink.config(&Config{SMTPSender: "website@example.com"})
+	config.SMTPTLSMode = smtpTLSStartTLS
+	transport, err := newSMTPTransport(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport.rootCAs = pool
+	err = transport.send(config.SMTPSender, &Email{From: config.SMTPSender, To: []string{"jane@example.com"}, Subject: "Hello"})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("got error %v, want STARTTLS error", err)
+	}
+	if len(sink.received()) != 0 {
+		t.Fatal("email sent without TLS")
+	}
+}
+
+func TestSendmailTransport(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "sendmail")
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > \""+dir+"/args\"\ncat > \""+dir+"/stdin\"\n"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send, err := newEmailTransport(&Config{EmailTransport: "sendmail", SendmailPath: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = send(&Email{From: "Website <website@example.com>", To: []string{"Jane <jane@example.com>", "john@example.org"}, Subject: "Hello", PlainTextBody: "Hello\nJane"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "-i -f website@example.com -- jane@example.com john@example.org\n"; string(args) != want {
+		t.Errorf("got arguments %q, want %q", args, want)
+	}
+	stdin, err := os.ReadFile(filepath.Join(dir, "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(stdin), "\r") {
+		t.Errorf("message piped to sendmail has CRLF line endings")
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(stdin)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Header.Get("Subject") != "Hello" {
+		t.Errorf("got subject %q", msg.Header.Get("Subject"))
+	}
+
+	// Errors include the output of sendmail
+	err = os.WriteFile(script, []byte("#!/bin/sh\necho 'no such user' >&2\nexit 67\n"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = send(&Email{From: "website@example.com", To: []string{"nobody@example.com"}, Subject: "Hello"})
+	if err == nil || !strings.Contains(err.Error(), "no such user") {
+		t.Fatalf("got error %v, want the sendmail output", err)
+	}
+}
+
+func TestFileDropTransport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "maildir")
+	send, err := newEmailTransport(&Config{EmailTransport: "file", EmailDropDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = send(&Email{From: "website@example.com", To: []string{"jane@example.com"}, Subject: "Hello", PlainTextBody: "Hello Jane"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, subdir := range []string{"tmp", "cur"} {
+		entries, err := os.ReadDir(filepath.Join(dir, subdir))
+		if err != nil || len(entries) != 0 {
+			t.Fatalf("got %d files in %q (%v), want 0", len(entries), subdir, err)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !strings.HasSuffix(entries[0].Name(), ".eml") {
+		t.Fatalf("got files %v in \"new\", want one .eml file", entries)
+	}
+	f, err := os.Open(filepath.Join(dir, "new", entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	msg, err := mail.ReadMessage(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Header.Get("Subject") != "Hello" || msg.Header.Get("To") != "jane@example.com" {
+		t.Errorf("got headers %v", msg.Header)
+	}
+}
+
+func TestNewEmailTransportErrors(t *testing.T) {
+	tests := map[string]*Config{
+		"unknown transport":  {EmailTransport: "pigeon"},
+		"unknown TLS mode":   {SMTPTLSMode: "sometimes"},
+		"file without dir":   {EmailTransport: "file"},
+		"partial DKIM setup": {DKIMDomain: "example.com"},
+	}
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := newEmailTransport(config)
+			if err == nil {
+				t.Fatal("got no error")
+			}
+		})
+	}
+}
@@ -3,9 +3,14 @@ module github.com/ejuju/personal_website
 go 1.18
 
 require (
+	github.com/andybalholm/brotli v1.1.0
 	github.com/bmizerany/pat v0.0.0-20210406213842-e4b6760bdd6f
 	github.com/go-pdf/fpdf v0.8.0
 	go.etcd.io/bbolt v1.3.7
+	golang.org/x/image v0.18.0
 )
 
-require golang.org/x/sys v0.4.0 // indirect
+require (
+	golang.org/x/sys v0.4.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+)
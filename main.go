@@ -10,6 +10,15 @@ import (
 )
 
 func main() {
+	// Convert JSON Resume documents to a resume data file (ex: "import-jsonresume en:resume.json fr:resume_fr.json")
+	if len(os.Args) > 1 && os.Args[1] == "import-jsonresume" {
+		err := app.ImportJSONResume(os.Stdout, os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Init and run HTTP server
 	server := &http.Server{
 		Handler:        app.NewHTTPHandler(os.Getenv("MODE") != "PROD"),